@@ -0,0 +1,114 @@
+package autodiff
+
+import "math"
+
+// Dual - дуальное число a + bε, где ε² = 0.
+// Val хранит значение функции, Der - производную по выбранному направлению.
+type Dual struct {
+	Val float64
+	Der float64
+}
+
+// chain применяет скалярную функцию со значением f и производной df к числу a.
+func (a Dual) chain(f, df float64) Dual {
+	return Dual{Val: f, Der: df * a.Der}
+}
+
+func (a Dual) Add(b Dual) Dual {
+	return Dual{Val: a.Val + b.Val, Der: a.Der + b.Der}
+}
+
+func (a Dual) Sub(b Dual) Dual {
+	return Dual{Val: a.Val - b.Val, Der: a.Der - b.Der}
+}
+
+func (a Dual) Mul(b Dual) Dual {
+	return Dual{Val: a.Val * b.Val, Der: a.Der*b.Val + a.Val*b.Der}
+}
+
+func (a Dual) Div(b Dual) Dual {
+	return Dual{Val: a.Val / b.Val, Der: (a.Der*b.Val - a.Val*b.Der) / (b.Val * b.Val)}
+}
+
+func (a Dual) Neg() Dual {
+	return Dual{Val: -a.Val, Der: -a.Der}
+}
+
+func (a Dual) Scale(c float64) Dual {
+	return Dual{Val: c * a.Val, Der: c * a.Der}
+}
+
+func (a Dual) AddConst(c float64) Dual {
+	return Dual{Val: a.Val + c, Der: a.Der}
+}
+
+func (a Dual) Pow(p float64) Dual {
+	f, d1, _ := powDerivatives(a.Val, p)
+	return a.chain(f, d1)
+}
+
+// powDerivatives возвращает vᵖ и две первые производные p·vᵖ⁻¹ и p(p-1)·vᵖ⁻².
+// Слагаемое с нулевым коэффициентом равно нулю, даже если степень v при v = 0
+// бесконечна: иначе 0·Inf = NaN, например, у x^1 и x^0 в нуле.
+func powDerivatives(v, p float64) (f, d1, d2 float64) {
+	f = math.Pow(v, p)
+	if p != 0 {
+		d1 = p * math.Pow(v, p-1)
+	}
+	if p != 0 && p != 1 {
+		d2 = p * (p - 1) * math.Pow(v, p-2)
+	}
+	return f, d1, d2
+}
+
+func (a Dual) Exp() Dual {
+	e := math.Exp(a.Val)
+	return a.chain(e, e)
+}
+
+func (a Dual) Log() Dual {
+	return a.chain(math.Log(a.Val), 1/a.Val)
+}
+
+func (a Dual) Sin() Dual {
+	return a.chain(math.Sin(a.Val), math.Cos(a.Val))
+}
+
+func (a Dual) Cos() Dual {
+	return a.chain(math.Cos(a.Val), -math.Sin(a.Val))
+}
+
+func (a Dual) Sqrt() Dual {
+	s := math.Sqrt(a.Val)
+	return a.chain(s, 0.5/s)
+}
+
+func (a Dual) Value() float64 {
+	return a.Val
+}
+
+// Gradient вычисляет градиент функции f в точке x прямым режимом:
+// по одному проходу с дуальными числами на каждую координату.
+func Gradient(f func([]Dual) Dual, x []float64) []float64 {
+	n := len(x)
+	args := make([]Dual, n)
+	for i := range x {
+		args[i] = Dual{Val: x[i]}
+	}
+	grad := make([]float64, n)
+	for i := 0; i < n; i++ {
+		args[i].Der = 1 // Направление - i-й базисный вектор
+		grad[i] = f(args).Der
+		args[i].Der = 0
+	}
+	return grad
+}
+
+// DirectionalDerivative вычисляет производную f в точке x по направлению v за один проход.
+func DirectionalDerivative(f func([]Dual) Dual, x, v []float64) float64 {
+	args := make([]Dual, len(x))
+	for i := range x {
+		args[i] = Dual{Val: x[i], Der: v[i]}
+	}
+	return f(args).Der
+}
@@ -0,0 +1,115 @@
+package autodiff
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// HyperDual - гипердуальное число a + bε₁ + cε₂ + dε₁ε₂, где ε₁² = ε₂² = 0.
+// При затравке ε₁ по xᵢ и ε₂ по xⱼ коэффициент E12 равен ∂²f/∂xᵢ∂xⱼ точно,
+// без вычитания близких чисел, как в конечных разностях.
+type HyperDual struct {
+	Re  float64
+	E1  float64
+	E2  float64
+	E12 float64
+}
+
+// chain применяет скалярную функцию со значением f, первой производной df и второй d2f.
+func (a HyperDual) chain(f, df, d2f float64) HyperDual {
+	return HyperDual{
+		Re:  f,
+		E1:  df * a.E1,
+		E2:  df * a.E2,
+		E12: df*a.E12 + d2f*a.E1*a.E2,
+	}
+}
+
+func (a HyperDual) Add(b HyperDual) HyperDual {
+	return HyperDual{Re: a.Re + b.Re, E1: a.E1 + b.E1, E2: a.E2 + b.E2, E12: a.E12 + b.E12}
+}
+
+func (a HyperDual) Sub(b HyperDual) HyperDual {
+	return HyperDual{Re: a.Re - b.Re, E1: a.E1 - b.E1, E2: a.E2 - b.E2, E12: a.E12 - b.E12}
+}
+
+func (a HyperDual) Mul(b HyperDual) HyperDual {
+	return HyperDual{
+		Re:  a.Re * b.Re,
+		E1:  a.E1*b.Re + a.Re*b.E1,
+		E2:  a.E2*b.Re + a.Re*b.E2,
+		E12: a.E12*b.Re + a.E1*b.E2 + a.E2*b.E1 + a.Re*b.E12,
+	}
+}
+
+func (a HyperDual) Div(b HyperDual) HyperDual {
+	inv := b.chain(1/b.Re, -1/(b.Re*b.Re), 2/(b.Re*b.Re*b.Re)) // 1/b
+	return a.Mul(inv)
+}
+
+func (a HyperDual) Neg() HyperDual {
+	return a.Scale(-1)
+}
+
+func (a HyperDual) Scale(c float64) HyperDual {
+	return HyperDual{Re: c * a.Re, E1: c * a.E1, E2: c * a.E2, E12: c * a.E12}
+}
+
+func (a HyperDual) AddConst(c float64) HyperDual {
+	a.Re += c
+	return a
+}
+
+func (a HyperDual) Pow(p float64) HyperDual {
+	return a.chain(powDerivatives(a.Re, p))
+}
+
+func (a HyperDual) Exp() HyperDual {
+	e := math.Exp(a.Re)
+	return a.chain(e, e, e)
+}
+
+func (a HyperDual) Log() HyperDual {
+	return a.chain(math.Log(a.Re), 1/a.Re, -1/(a.Re*a.Re))
+}
+
+func (a HyperDual) Sin() HyperDual {
+	s, c := math.Sin(a.Re), math.Cos(a.Re)
+	return a.chain(s, c, -s)
+}
+
+func (a HyperDual) Cos() HyperDual {
+	s, c := math.Sin(a.Re), math.Cos(a.Re)
+	return a.chain(c, -s, -c)
+}
+
+func (a HyperDual) Sqrt() HyperDual {
+	s := math.Sqrt(a.Re)
+	return a.chain(s, 0.5/s, -0.25/(s*a.Re))
+}
+
+func (a HyperDual) Value() float64 {
+	return a.Re
+}
+
+// Hessian вычисляет матрицу Гессе функции f в точке x.
+// Требует n(n+1)/2 проходов: по одному на каждый элемент верхнего треугольника.
+func Hessian(f func([]HyperDual) HyperDual, x []float64) common_funcs.Matrix {
+	n := len(x)
+	args := make([]HyperDual, n)
+	for i := range x {
+		args[i] = HyperDual{Re: x[i]}
+	}
+	hess := common_funcs.NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		args[i].E1 = 1
+		for j := i; j < n; j++ {
+			args[j].E2 = 1
+			hess[i][j] = f(args).E12
+			hess[j][i] = hess[i][j] // Симметричная
+			args[j].E2 = 0
+		}
+		args[i].E1 = 0
+	}
+	return hess
+}
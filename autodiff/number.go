@@ -0,0 +1,35 @@
+// Package autodiff реализует автоматическое дифференцирование целевых функций.
+//
+// Функция записывается один раз в обобщенном виде для любого типа,
+// удовлетворяющего Number, например для варианта 17.164:
+//
+//	func f[T autodiff.Number[T]](x []T) T {
+//		x1, x2, x3 := x[0], x[1], x[2]
+//		return x1.Pow(4).Scale(2).Add(x2.Pow(4)).Add(x1.Pow(2).Mul(x2.Pow(2))).
+//			Add(x3.Pow(4)).Add(x1.Pow(2).Mul(x3.Pow(2))).Add(x1).Add(x2)
+//	}
+//
+//	obj := autodiff.NewObjective(3, f[autodiff.Dual], f[autodiff.HyperDual])
+//
+// После этого obj можно передавать в newtonMethod, quasiNewtonRank1 и conjugateGradient:
+// градиент считается дуальными числами, а Гессиан - гипердуальными, без ошибок округления.
+package autodiff
+
+// Number - набор операций, через которые записывается дифференцируемая функция.
+// Константы задаются через Scale и AddConst, так как в Go нет перегрузки операторов.
+type Number[T any] interface {
+	Add(b T) T            // a + b
+	Sub(b T) T            // a - b
+	Mul(b T) T            // a * b
+	Div(b T) T            // a / b
+	Neg() T               // -a
+	Scale(c float64) T    // c * a
+	AddConst(c float64) T // a + c
+	Pow(p float64) T      // a^p
+	Exp() T               // e^a
+	Log() T               // ln(a)
+	Sin() T               // sin(a)
+	Cos() T               // cos(a)
+	Sqrt() T              // √a
+	Value() float64       // Вещественная часть числа
+}
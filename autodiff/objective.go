@@ -0,0 +1,49 @@
+package autodiff
+
+import (
+	"fmt"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// objective - целевая функция, производные которой вычисляются автоматически.
+type objective struct {
+	dim    int
+	fDual  func([]Dual) Dual
+	fHyper func([]HyperDual) HyperDual
+}
+
+// NewObjective создает целевую функцию размерности dim из двух экземпляров одной
+// обобщенной функции: fDual используется для значения и градиента, fHyper - для Гессиана.
+func NewObjective(dim int, fDual func([]Dual) Dual, fHyper func([]HyperDual) HyperDual) common_funcs.HessianObjective {
+	return objective{dim: dim, fDual: fDual, fHyper: fHyper}
+}
+
+func (o objective) Value(x []float64) float64 {
+	o.checkDim(x)
+	args := make([]Dual, len(x))
+	for i := range x {
+		args[i] = Dual{Val: x[i]}
+	}
+	return o.fDual(args).Val
+}
+
+func (o objective) Gradient(x []float64) []float64 {
+	o.checkDim(x)
+	return Gradient(o.fDual, x)
+}
+
+func (o objective) Hessian(x []float64) common_funcs.Matrix {
+	o.checkDim(x)
+	return Hessian(o.fHyper, x)
+}
+
+func (o objective) Dimension() int {
+	return o.dim
+}
+
+// checkDim проверяет размерность входного вектора.
+func (o objective) checkDim(x []float64) {
+	if len(x) != o.dim {
+		panic(fmt.Sprintf("Целевая функция ожидает %d-мерный вектор, получено: %d", o.dim, len(x)))
+	}
+}
@@ -0,0 +1,265 @@
+package autodiff
+
+import (
+	"fmt"
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// Tape - лента обратного режима автоматического дифференцирования.
+// Каждая операция над Var записывает на ленту узел с локальными частными
+// производными, после чего весь градиент находится одним обратным проходом,
+// независимо от размерности (в отличие от прямого режима с n проходами).
+//
+// Значения узлов хранятся дуальными числами: Der - производная по направлению v,
+// заданному при создании переменных. Тогда тот же обратный проход дает и
+// произведение Гессиана на вектор v ("прямой поверх обратного" режим).
+type Tape struct {
+	nodes []node
+}
+
+// node - одна записанная операция.
+type node struct {
+	val      Dual    // Значение и его производная по направлению v
+	parents  [2]int  // Индексы аргументов на ленте
+	partials [2]Dual // Локальные частные производные по аргументам
+	arity    int     // Количество аргументов (0 для входных переменных)
+}
+
+// Var - переменная, записанная на ленту. Реализует Number[Var].
+type Var struct {
+	tape *Tape
+	idx  int
+}
+
+// NewTape создает пустую ленту с запасом памяти под capacity узлов.
+func NewTape(capacity int) *Tape {
+	return &Tape{nodes: make([]node, 0, capacity)}
+}
+
+// Reset очищает ленту, сохраняя выделенную память.
+func (t *Tape) Reset() {
+	t.nodes = t.nodes[:0]
+}
+
+// Variable добавляет на ленту входную переменную со значением x и направлением v.
+func (t *Tape) Variable(x, v float64) Var {
+	t.nodes = append(t.nodes, node{val: Dual{Val: x, Der: v}})
+	return Var{tape: t, idx: len(t.nodes) - 1}
+}
+
+// Variables добавляет на ленту вектор входных переменных.
+// v может быть nil, если произведение Гессиана на вектор не нужно.
+func (t *Tape) Variables(x, v []float64) []Var {
+	vars := make([]Var, len(x))
+	for i := range x {
+		dir := 0.0
+		if v != nil {
+			dir = v[i]
+		}
+		vars[i] = t.Variable(x[i], dir)
+	}
+	return vars
+}
+
+// Backward выполняет обратный проход от выхода out и возвращает сопряженные
+// переменные (adjoint) для всех узлов: Val - частная производная out по узлу,
+// Der - её производная по направлению v.
+func (t *Tape) Backward(out Var) []Dual {
+	if out.tape != t {
+		panic("Переменная записана на другую ленту")
+	}
+	adj := make([]Dual, out.idx+1)
+	adj[out.idx] = Dual{Val: 1}
+	for k := out.idx; k >= 0; k-- {
+		n := &t.nodes[k]
+		for p := 0; p < n.arity; p++ {
+			adj[n.parents[p]] = adj[n.parents[p]].Add(adj[k].Mul(n.partials[p]))
+		}
+	}
+	return adj
+}
+
+// unary записывает операцию с одним аргументом a, значением f и производной df.
+func (a Var) unary(f, df Dual) Var {
+	t := a.tape
+	t.nodes = append(t.nodes, node{val: f, parents: [2]int{a.idx}, partials: [2]Dual{df}, arity: 1})
+	return Var{tape: t, idx: len(t.nodes) - 1}
+}
+
+// binary записывает операцию с двумя аргументами a и b.
+func (a Var) binary(b Var, f, da, db Dual) Var {
+	if a.tape != b.tape {
+		panic("Операция над переменными с разных лент")
+	}
+	t := a.tape
+	t.nodes = append(t.nodes, node{val: f, parents: [2]int{a.idx, b.idx}, partials: [2]Dual{da, db}, arity: 2})
+	return Var{tape: t, idx: len(t.nodes) - 1}
+}
+
+// dual возвращает значение переменной вместе с производной по направлению.
+func (a Var) dual() Dual {
+	return a.tape.nodes[a.idx].val
+}
+
+// lift вычисляет значение и локальную производную скалярной функции g
+// с производными dg и d2g, продолжая их дуальными числами по направлению v.
+func (a Var) lift(g, dg, d2g float64) Var {
+	x := a.dual()
+	return a.unary(Dual{Val: g, Der: dg * x.Der}, Dual{Val: dg, Der: d2g * x.Der})
+}
+
+func (a Var) Add(b Var) Var {
+	return a.binary(b, a.dual().Add(b.dual()), Dual{Val: 1}, Dual{Val: 1})
+}
+
+func (a Var) Sub(b Var) Var {
+	return a.binary(b, a.dual().Sub(b.dual()), Dual{Val: 1}, Dual{Val: -1})
+}
+
+func (a Var) Mul(b Var) Var {
+	x, y := a.dual(), b.dual()
+	return a.binary(b, x.Mul(y), y, x)
+}
+
+func (a Var) Div(b Var) Var {
+	x, y := a.dual(), b.dual()
+	inv := Dual{Val: 1}.Div(y) // 1/b
+	return a.binary(b, x.Mul(inv), inv, x.Mul(inv).Mul(inv).Neg())
+}
+
+func (a Var) Neg() Var {
+	return a.unary(a.dual().Neg(), Dual{Val: -1})
+}
+
+func (a Var) Scale(c float64) Var {
+	return a.unary(a.dual().Scale(c), Dual{Val: c})
+}
+
+func (a Var) AddConst(c float64) Var {
+	return a.unary(a.dual().AddConst(c), Dual{Val: 1})
+}
+
+func (a Var) Pow(p float64) Var {
+	return a.lift(powDerivatives(a.dual().Val, p))
+}
+
+func (a Var) Exp() Var {
+	e := math.Exp(a.dual().Val)
+	return a.lift(e, e, e)
+}
+
+func (a Var) Log() Var {
+	v := a.dual().Val
+	return a.lift(math.Log(v), 1/v, -1/(v*v))
+}
+
+func (a Var) Sin() Var {
+	v := a.dual().Val
+	return a.lift(math.Sin(v), math.Cos(v), -math.Sin(v))
+}
+
+func (a Var) Cos() Var {
+	v := a.dual().Val
+	return a.lift(math.Cos(v), -math.Sin(v), -math.Cos(v))
+}
+
+func (a Var) Sqrt() Var {
+	v := a.dual().Val
+	s := math.Sqrt(v)
+	return a.lift(s, 0.5/s, -0.25/(s*v))
+}
+
+func (a Var) Value() float64 {
+	return a.dual().Val
+}
+
+// ReverseGradient вычисляет значение и градиент f в точке x за один прямой
+// и один обратный проход.
+func ReverseGradient(f func([]Var) Var, x []float64) (float64, []float64) {
+	t := NewTape(8 * len(x))
+	vars := t.Variables(x, nil)
+	out := f(vars)
+	adj := t.Backward(out)
+	grad := make([]float64, len(x))
+	for i, v := range vars {
+		grad[i] = adj[v.idx].Val
+	}
+	return out.Value(), grad
+}
+
+// HessianVectorProduct вычисляет произведение Гессиана f в точке x на вектор v
+// за один прямой и один обратный проход, не строя матрицу Гессе.
+func HessianVectorProduct(f func([]Var) Var, x, v []float64) []float64 {
+	t := NewTape(8 * len(x))
+	vars := t.Variables(x, v)
+	adj := t.Backward(f(vars))
+	hv := make([]float64, len(x))
+	for i, xv := range vars {
+		hv[i] = adj[xv.idx].Der
+	}
+	return hv
+}
+
+// reverseObjective - целевая функция с производными обратного режима.
+type reverseObjective struct {
+	dim int
+	f   func([]Var) Var
+}
+
+// NewReverseObjective создает целевую функцию размерности dim, градиент которой
+// считается обратным режимом. Подходит для задач с тысячами переменных.
+// Помимо Objective реализует HessianVectorObjective и HessianObjective
+// (Гессиан собирается из n произведений на базисные векторы).
+func NewReverseObjective(dim int, f func([]Var) Var) common_funcs.HessianObjective {
+	return reverseObjective{dim: dim, f: f}
+}
+
+func (o reverseObjective) Value(x []float64) float64 {
+	o.checkDim(x)
+	return valueOnly(o.f, x)
+}
+
+func (o reverseObjective) Gradient(x []float64) []float64 {
+	o.checkDim(x)
+	_, grad := ReverseGradient(o.f, x)
+	return grad
+}
+
+func (o reverseObjective) HessianVector(x, v []float64) []float64 {
+	o.checkDim(x)
+	return HessianVectorProduct(o.f, x, v)
+}
+
+func (o reverseObjective) Hessian(x []float64) common_funcs.Matrix {
+	o.checkDim(x)
+	n := len(x)
+	hess := common_funcs.NewMatrix(n, n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		col := HessianVectorProduct(o.f, x, e)
+		for i := 0; i < n; i++ {
+			hess[i][j] = col[i]
+		}
+		e[j] = 0
+	}
+	return hess
+}
+
+func (o reverseObjective) Dimension() int {
+	return o.dim
+}
+
+// checkDim проверяет размерность входного вектора.
+func (o reverseObjective) checkDim(x []float64) {
+	if len(x) != o.dim {
+		panic(fmt.Sprintf("Целевая функция ожидает %d-мерный вектор, получено: %d", o.dim, len(x)))
+	}
+}
+
+// valueOnly вычисляет только значение функции, записывая ленту без обратного прохода.
+func valueOnly(f func([]Var) Var, x []float64) float64 {
+	t := NewTape(8 * len(x))
+	return f(t.Variables(x, nil)).Value()
+}
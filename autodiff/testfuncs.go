@@ -0,0 +1,17 @@
+package autodiff
+
+// ExtendedRosenbrock - расширенная функция Розенброка
+// f(x) = Σ 100(x₂ᵢ - x₂ᵢ₋₁²)² + (1 - x₂ᵢ₋₁)² (размерность n четная),
+// стандартная тестовая задача большой размерности с минимумом в (1, ..., 1).
+// Для тысяч переменных используется вместе с NewReverseObjective:
+//
+//	obj := autodiff.NewReverseObjective(n, autodiff.ExtendedRosenbrock[autodiff.Var])
+func ExtendedRosenbrock[T Number[T]](x []T) T {
+	sum := x[0].Scale(0)
+	for i := 0; i+1 < len(x); i += 2 {
+		a := x[i+1].Sub(x[i].Pow(2))
+		b := x[i].Neg().AddConst(1)
+		sum = sum.Add(a.Pow(2).Scale(100)).Add(b.Pow(2))
+	}
+	return sum
+}
@@ -0,0 +1,115 @@
+// Команда check_derivatives сверяет аналитические градиент и Гессиан целевой
+// функции с конечными разностями в случайных и заданных точках.
+// Завершается с ненулевым кодом, если найдено расхождение.
+//
+//	go run ./cmd/check_derivatives -random 10 -points "0,0,0;1,-1,0.5"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/finite_diff"
+)
+
+// objectives - целевые функции, доступные для проверки по имени.
+var objectives = map[string]common_funcs.Objective{
+	"17.164": common_funcs.Task17164,
+	"exp":    common_funcs.TaskExp,
+}
+
+// parsePoints разбирает список точек вида "x1,x2,x3;y1,y2,y3".
+func parsePoints(s string, dim int) ([][]float64, error) {
+	var points [][]float64
+	for _, p := range strings.Split(s, ";") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		fields := strings.Split(p, ",")
+		if len(fields) != dim {
+			return nil, fmt.Errorf("точка %q: ожидается %d координат, получено %d", p, dim, len(fields))
+		}
+		point := make([]float64, dim)
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil {
+				return nil, fmt.Errorf("точка %q: %v", p, err)
+			}
+			point[i] = v
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// parseScheme разбирает название разностной схемы.
+func parseScheme(s string) (finite_diff.Scheme, error) {
+	switch s {
+	case "forward":
+		return finite_diff.Forward, nil
+	case "central":
+		return finite_diff.Central, nil
+	case "richardson":
+		return finite_diff.Richardson, nil
+	}
+	return 0, fmt.Errorf("неизвестная схема %q (forward, central, richardson)", s)
+}
+
+func main() {
+	funcName := flag.String("func", "17.164", "имя целевой функции")
+	formula := flag.String("f", "", "целевая функция в виде формулы (вместо -func)")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции (вместо -func)")
+	pointsFlag := flag.String("points", "", "точки проверки через ';', координаты через ','")
+	random := flag.Int("random", 5, "количество случайных точек")
+	radius := flag.Float64("radius", 2.0, "случайные точки берутся из куба [-radius, radius]^n")
+	seed := flag.Int64("seed", 1, "зерно генератора случайных точек")
+	tol := flag.Float64("tol", 0, "допустимая относительная ошибка (0 - по схеме: 1e-3 для forward, 1e-5 для central, 1e-6 для richardson)")
+	symTol := flag.Float64("symtol", finite_diff.DefaultCheckOptions.SymTol, "допустимая несимметричность Гессиана")
+	schemeName := flag.String("scheme", "richardson", "разностная схема: forward, central, richardson")
+	flag.Parse()
+
+	obj, ok := objectives[*funcName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Неизвестная целевая функция %q\n", *funcName)
+		os.Exit(2)
+	}
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка в формуле целевой функции:", err)
+		os.Exit(2)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	scheme, err := parseScheme(*schemeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	points, err := parsePoints(*pointsFlag, obj.Dimension())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	points = append(points, finite_diff.RandomPoints(rng, *random, obj.Dimension(), *radius)...)
+
+	opts := finite_diff.CheckOptionsFor(scheme)
+	opts.SymTol = *symTol
+	if *tol > 0 {
+		opts.Tol = *tol
+	}
+	reports := finite_diff.CheckDerivatives(obj, points, opts)
+	if !finite_diff.PrintReports(os.Stdout, reports) {
+		fmt.Println("\nНайдены расхождения производных.")
+		os.Exit(1)
+	}
+	fmt.Println("\nПроизводные согласованы во всех точках.")
+}
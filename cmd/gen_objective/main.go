@@ -0,0 +1,70 @@
+// Команда gen_objective генерирует Go-файл с функциями F, GradF и Hessian
+// по формуле целевой функции. Предназначена для go:generate, например:
+//
+//	//go:generate go run ../cmd/gen_objective -f "x1^2 + exp(x2)" -suffix Exp -o variant_exp_gen.go
+//
+// Имя пакета по умолчанию берется из переменной окружения GOPACKAGE,
+// которую выставляет go generate.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"optimizationMethodsTask4/expr"
+)
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "имя пакета сгенерированного файла")
+	suffix := flag.String("suffix", "", "суффикс имен функций: F<suffix>, GradF<suffix>, Hessian<suffix>")
+	objective := flag.String("objective", "", "имя переменной с целевой функцией (пусто - не создавать)")
+	out := flag.String("o", "", "выходной файл (по умолчанию - стандартный вывод)")
+	flag.Parse()
+
+	src := *formula
+	if *formulaFile != "" {
+		var err error
+		if src, err = expr.ReadFormulaFile(*formulaFile); err != nil {
+			fail(err)
+		}
+	}
+	if strings.TrimSpace(src) == "" {
+		fail(fmt.Errorf("не задана формула (-f или -file)"))
+	}
+	if *pkg == "" {
+		*pkg = "common_funcs"
+	}
+
+	e, err := expr.Parse(src)
+	if err != nil {
+		fail(err)
+	}
+	code, err := expr.GenerateGo(e, expr.GoOptions{
+		Package:   *pkg,
+		Suffix:    *suffix,
+		Objective: *objective,
+		Formula:   strings.Join(strings.Fields(src), " "),
+		Command:   "gen_objective",
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+// fail печатает ошибку и завершает работу с ненулевым кодом.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "gen_objective:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,47 @@
+// Команда one_dim сравнивает методы одномерной минимизации по количеству
+// вычислений функции одной переменной, заданной формулой.
+//
+//	go run ./cmd/one_dim -f "(x - 2)^2 + exp(x)" -a -5 -b 5
+//	go run ./cmd/one_dim -f "x^4 - 3*x" -swann 0.1
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/one_dim"
+)
+
+func main() {
+	formula := flag.String("f", "(x - 2)^2 + exp(x)", "функция одной переменной")
+	a := flag.Float64("a", -5, "левый конец отрезка")
+	b := flag.Float64("b", 5, "правый конец отрезка")
+	tol := flag.Float64("tol", 1e-6, "точность по x")
+	swann := flag.Float64("swann", 0, "начальный шаг метода Свенна от точки 0 (0 - использовать [a, b])")
+	flag.Parse()
+
+	e, err := expr.Parse(*formula)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка в формуле:", err)
+		os.Exit(1)
+	}
+	if e.Dimension() != 1 {
+		fmt.Fprintf(os.Stderr, "Функция должна зависеть от одной переменной, получено: %d\n", e.Dimension())
+		os.Exit(2)
+	}
+	compiled := e.Compile()
+	f := func(x float64) float64 { return compiled([]float64{x}) }
+
+	lo, hi := *a, *b
+	if *swann > 0 {
+		var evals int
+		lo, hi, evals = one_dim.Swann(f, *swann, 60)
+		fmt.Printf("Метод Свенна: отрезок [%.6f, %.6f], вычислений f: %d\n", lo, hi, evals)
+	}
+	for _, name := range one_dim.MethodNames() {
+		method, _ := one_dim.MethodByName(name)
+		fmt.Printf("%-16s %s\n", method.Name+":", method.Minimize(f, lo, hi, *tol))
+	}
+}
@@ -0,0 +1,60 @@
+// Команда symbolic печатает символьные градиент и Гессиан целевой функции,
+// заданной формулой, чтобы их можно было сверить с ручным выводом GradF и Hessian.
+//
+//	go run ./cmd/symbolic -f "2*x1^4 + x2^4 + x1^2*x2^2 + x3^4 + x1^2*x3^2 + x1 + x2"
+//	go run ./cmd/symbolic -file task.txt -latex
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"optimizationMethodsTask4/expr"
+)
+
+// task17164 - формула варианта 17.164 (используется по умолчанию).
+const task17164 = "2*x1^4 + x2^4 + x1^2*x2^2 + x3^4 + x1^2*x3^2 + x1 + x2"
+
+func main() {
+	formula := flag.String("f", task17164, "целевая функция в виде формулы")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	latex := flag.Bool("latex", false, "печатать в нотации LaTeX")
+	flag.Parse()
+
+	src := *formula
+	if *formulaFile != "" {
+		var err error
+		if src, err = expr.ReadFormulaFile(*formulaFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	e, err := expr.Parse(src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+
+	format := (*expr.Expr).String
+	if *latex {
+		format = (*expr.Expr).LaTeX
+	}
+
+	fmt.Println("f =", format(e.Simplify()))
+	fmt.Println("\nГрадиент:")
+	for i, g := range e.Gradient() {
+		fmt.Printf("grad[%d] = %s\n", i, format(g))
+	}
+	fmt.Println("\nГессиан:")
+	hess := e.Hessian()
+	for i := range hess {
+		for j := range hess[i] {
+			if j < i {
+				fmt.Printf("hess[%d][%d] = hess[%d][%d] // Симметричная\n", i, j, j, i)
+				continue
+			}
+			fmt.Printf("hess[%d][%d] = %s\n", i, j, format(hess[i][j]))
+		}
+	}
+}
@@ -3,53 +3,14 @@ package common_funcs
 import (
 	"fmt"
 	"math"
+	"optimizationMethodsTask4/one_dim"
+	"strings"
 )
 
-/*// F вычисляет значение целевой функции для трехмерного вектора x.
-// f(x₁, x₂, x₃) = x₁² + 2x₂² + x₁²x₂² + 2x₃ + e^(x₂² + x₃²) - x₂
-func F(x []float64) float64 {
-	// Проверка размерности входного вектора
-	if len(x) != 3 {
-		panic(fmt.Sprintf("Функция F ожидает 3-мерный вектор, получено: %d", len(x)))
-	}
-	x1 := x[0]
-	x2 := x[1]
-	x3 := x[2]
-	term1 := math.Pow(x1, 2)
-	term2 := 2 * math.Pow(x2, 2)
-	term3 := math.Pow(x1, 2) * math.Pow(x2, 2)
-	term4 := 2 * x3 // Объединенные x3 + x3
-	term5 := math.Exp(math.Pow(x2, 2) + math.Pow(x3, 2))
-	term6 := -x2
-	return term1 + term2 + term3 + term4 + term5 + term6
-}
-*/
-// GradF вычисляет градиент НОВОЙ целевой функции в точке x.
-// Возвращает слайс []float64 размерности 3.
-//func GradF(x []float64) []float64 {
-//	// Проверка размерности входного вектора
-//	if len(x) != 3 {
-//		panic(fmt.Sprintf("Функция GradF ожидает 3-мерный вектор, получено: %d", len(x)))
-//	}
-//	x1 := x[0]
-//	x2 := x[1]
-//	x3 := x[2]
-//	grad := make([]float64, 3) // Градиент теперь 3-мерный
-//
-//	// Частная производная по x1: ∂f/∂x₁ = 2x₁ + 2x₁x₂²
-//	grad[0] = 2*x1 + 2*x1*math.Pow(x2, 2)
-//
-//	// Экспоненциальный член, используемый в производных по x2 и x3
-//	expTerm := math.Exp(math.Pow(x2, 2) + math.Pow(x3, 2))
-//
-//	// Частная производная по x2: ∂f/∂x₂ = 4x₂ + 2x₁²x₂ + 2x₂e^(x₂² + x₃²) - 1
-//	grad[1] = 4*x2 + 2*math.Pow(x1, 2)*x2 + 2*x2*expTerm - 1
-//
-//	// Частная производная по x3: ∂f/∂x₃ = 2 + 2x₃e^(x₂² + x₃²)
-//	grad[2] = 2 + 2*x3*expTerm
-//
-//	return grad
-//}
+// Вариант с экспонентой f = x₁² + 2x₂² + x₁²x₂² + 2x₃ + e^(x₂² + x₃²) - x₂
+// генерируется из формулы в variant_exp_gen.go (FExp, GradFExp, HessianExp, TaskExp).
+// Новые варианты добавляются так же: директивой go:generate, а не вручную.
+//go:generate go run ../cmd/gen_objective -suffix Exp -objective TaskExp -o variant_exp_gen.go -f "x1^2 + 2*x2^2 + x1^2*x2^2 + 2*x3 + exp(x2^2 + x3^2) - x2"
 
 // f(x₁, x₂, x₃) = 2x₁⁴ + x₂⁴ + x₁²x₂² + x₃⁴ + x₁²x₃² + x₁ + x₂
 func F(x []float64) float64 {
@@ -131,31 +92,12 @@ func ScalarMult(s float64, v []float64) []float64 {
 }
 
 // --- Одномерный поиск методом Золотого сечения ---
-// Находит alpha, минимизирующее f(x + alpha*direction) в интервале [a, b]
-func GoldenSectionSearch(x, direction []float64, a, b, tol float64) float64 {
-	phi := (1 + math.Sqrt(5)) / 2
-	resPhi := 2 - phi
-	x1 := a + resPhi*(b-a)
-	x2 := b - resPhi*(b-a)
-	f1 := F(VectorAdd(x, ScalarMult(x1, direction))) // Используем F из этого же пакета
-	f2 := F(VectorAdd(x, ScalarMult(x2, direction))) // Используем F из этого же пакета
-
-	for math.Abs(b-a) > tol {
-		if f1 < f2 {
-			b = x2
-			x2 = x1
-			f2 = f1
-			x1 = a + resPhi*(b-a)
-			f1 = F(VectorAdd(x, ScalarMult(x1, direction)))
-		} else {
-			a = x1
-			x1 = x2
-			f1 = f2
-			x2 = b - resPhi*(b-a)
-			f2 = F(VectorAdd(x, ScalarMult(x2, direction)))
-		}
+// Находит alpha, минимизирующее obj(x + alpha*direction) в интервале [a, b]
+func GoldenSectionSearch(obj Objective, x, direction []float64, a, b, tol float64) float64 {
+	phi := func(alpha float64) float64 {
+		return obj.Value(VectorAdd(x, ScalarMult(alpha, direction)))
 	}
-	return (a + b) / 2
+	return one_dim.GoldenSection(phi, a, b, tol).X
 }
 
 func DotProduct(a, b []float64) float64 {
@@ -191,31 +133,6 @@ func IdentityMatrix(n int) Matrix {
 	return mat
 }
 
-//
-//// Hessian вычисляет матрицу Гессе для НОВОЙ функции в точке x.
-//func Hessian(x []float64) Matrix {
-//	if len(x) != 3 {
-//		panic(fmt.Sprintf("Функция Hessian ожидает 3-мерный вектор, получено: %d", len(x)))
-//	}
-//	x1 := x[0]
-//	x2 := x[1]
-//	x3 := x[2]
-//	hess := NewMatrix(3, 3)
-//	expTerm := math.Exp(math.Pow(x2, 2) + math.Pow(x3, 2))
-//
-//	hess[0][0] = 2 + 2*math.Pow(x2, 2)
-//	hess[0][1] = 4 * x1 * x2
-//	hess[0][2] = 0
-//	hess[1][0] = hess[0][1] // Симметричная матрица
-//	hess[1][1] = 4 + 2*math.Pow(x1, 2) + 2*expTerm + 4*math.Pow(x2, 2)*expTerm
-//	hess[1][2] = 4 * x2 * x3 * expTerm
-//	hess[2][0] = hess[0][2] // Симметричная матрица
-//	hess[2][1] = hess[1][2] // Симметричная матрица
-//	hess[2][2] = 2*expTerm + 4*math.Pow(x3, 2)*expTerm
-//
-//	return hess
-//}
-
 func Hessian(x []float64) Matrix {
 	if len(x) != 3 {
 		panic(fmt.Sprintf("Функция Hessian (17.164) ожидает 3-мерный вектор, получено: %d", len(x)))
@@ -238,38 +155,6 @@ func Hessian(x []float64) Matrix {
 	return hess
 }
 
-// Inverse3x3 вычисляет обратную матрицу для матрицы 3x3.
-// Возвращает обратную матрицу и флаг bool (true, если обращение успешно).
-// Использует метод алгебраических дополнений.
-func Inverse3x3(m Matrix) (Matrix, bool) {
-	if len(m) != 3 || len(m[0]) != 3 {
-		panic("Inverse3x3 работает только для матриц 3x3")
-	}
-	inv := NewMatrix(3, 3)
-	// Вычисляем определитель
-	det := m[0][0]*(m[1][1]*m[2][2]-m[2][1]*m[1][2]) -
-		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
-		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
-
-	if math.Abs(det) < 1e-12 { // Проверка на вырожденность матрицы
-		return inv, false // Невозможно обратить
-	}
-
-	invDet := 1.0 / det
-
-	inv[0][0] = (m[1][1]*m[2][2] - m[2][1]*m[1][2]) * invDet
-	inv[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) * invDet
-	inv[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) * invDet
-	inv[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) * invDet
-	inv[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) * invDet
-	inv[1][2] = (m[1][0]*m[0][2] - m[0][0]*m[1][2]) * invDet
-	inv[2][0] = (m[1][0]*m[2][1] - m[2][0]*m[1][1]) * invDet
-	inv[2][1] = (m[2][0]*m[0][1] - m[0][0]*m[2][1]) * invDet
-	inv[2][2] = (m[0][0]*m[1][1] - m[1][0]*m[0][1]) * invDet
-
-	return inv, true
-}
-
 // MatrixVectorMult умножает матрицу на вектор: res = m * v.
 func MatrixVectorMult(m Matrix, v []float64) []float64 {
 	rows := len(m)
@@ -329,3 +214,12 @@ func MatrixScalarMult(s float64, m Matrix) Matrix {
 	}
 	return res
 }
+
+// FormatVector форматирует вектор для вывода: [x₁, x₂, ..., xₙ] с 6 знаками после запятой.
+func FormatVector(v []float64) string {
+	parts := make([]string, len(v))
+	for i, val := range v {
+		parts[i] = fmt.Sprintf("%.6f", val)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
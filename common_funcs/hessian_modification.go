@@ -0,0 +1,176 @@
+package common_funcs
+
+import "math"
+
+// HessianModification - способ сделать Гессиан положительно определенным,
+// чтобы шаг Ньютона был направлением спуска, не теряя информацию о кривизне.
+type HessianModification int
+
+const (
+	NoModification     HessianModification = iota // Без модификации (переход на антиградиент)
+	LevenbergShift                                // H + τI, τ увеличивается до успеха Холецкого
+	GillMurrayCholesky                            // Модифицированное разложение Холецкого Гилла-Мюррея
+	EigenvalueFlip                                // Замена собственных значений λ на max(|λ|, δ)
+)
+
+// String возвращает название способа для вывода.
+func (m HessianModification) String() string {
+	switch m {
+	case NoModification:
+		return "без модификации"
+	case LevenbergShift:
+		return "сдвиг Левенберга"
+	case GillMurrayCholesky:
+		return "модифицированный Холецкий (Гилл-Мюррей)"
+	case EigenvalueFlip:
+		return "отражение собственных значений"
+	default:
+		return "неизвестная модификация"
+	}
+}
+
+// minCurvature - относительная нижняя граница кривизны модифицированного Гессиана.
+// При слишком малой границе (порядка машинной точности) в точке с нулевым Гессианом,
+// как в начальной точке варианта 17.164, шаг становится огромным и одномерный поиск
+// на [0, lineSearchMaxAlpha] уже не может его разрешить.
+const minCurvature = 1e-3
+
+// ModifyHessian строит положительно определенную матрицу H + E по Гессиану h
+// выбранным способом. Возвращает функцию решения системы (H + E) p = b и
+// величину модификации: τ для сдвига Левенберга, max Eⱼⱼ для Гилла-Мюррея,
+// максимальное изменение собственного значения для отражения.
+// Если h содержит NaN или ±Inf или сдвиг Левенберга не помог, возвращается
+// E = ∞: solve(b) = b, то есть при b = -grad - шаг по антиградиенту.
+func ModifyHessian(h Matrix, method HessianModification) (solve func([]float64) []float64, shift float64) {
+	steepestDescent := func(b []float64) []float64 {
+		return append([]float64(nil), b...)
+	}
+	if method != NoModification && !h.IsFinite() {
+		return steepestDescent, math.Inf(1)
+	}
+	switch method {
+	case LevenbergShift:
+		c, tau, ok := levenbergShift(h)
+		if !ok {
+			return steepestDescent, math.Inf(1)
+		}
+		return c.Solve, tau
+	case GillMurrayCholesky:
+		f, shift := gillMurray(h)
+		return f.Solve, shift
+	case EigenvalueFlip:
+		return eigenvalueFlip(h)
+	case NoModification:
+		panic("NoModification не модифицирует Гессиан")
+	default:
+		panic("Неизвестный способ модификации Гессиана")
+	}
+}
+
+// maxShiftDoublings - предельное число удвоений τ в levenbergShift: для конечной
+// матрицы сдвиг β·2⁶⁰ заведомо делает ее диагонально преобладающей.
+const maxShiftDoublings = 60
+
+// levenbergShift подбирает τ ≥ 0 такое, что H + τI допускает разложение Холецкого
+// (алгоритм 3.3 из Нокедала-Райта): τ удваивается, начиная с -min Hᵢᵢ + β.
+// Возвращает false, если за maxShiftDoublings удвоений разложение не удалось.
+func levenbergShift(h Matrix) (*Cholesky, float64, bool) {
+	beta := minCurvature * math.Max(1, h.Norm1())
+	n := len(h)
+	minDiag := math.Inf(1)
+	for i := 0; i < n; i++ {
+		minDiag = math.Min(minDiag, h[i][i])
+	}
+	tau := 0.0
+	if minDiag <= 0 {
+		tau = -minDiag + beta
+	}
+	for k := 0; k <= maxShiftDoublings; k++ {
+		shifted := h.Copy()
+		for i := 0; i < n; i++ {
+			shifted[i][i] += tau
+		}
+		if c, ok := shifted.Cholesky(); ok {
+			return c, tau, true
+		}
+		tau = math.Max(2*tau, beta)
+	}
+	return nil, tau, false
+}
+
+// gillMurray вычисляет модифицированное разложение Холецкого H + E = L D Lᵀ
+// (Гилл, Мюррей, Райт): диагональ D ограничивается снизу так, чтобы элементы L
+// оставались ограниченными, а E - диагональная и минимально необходимая.
+func gillMurray(h Matrix) (*LDLT, float64) {
+	n := len(h)
+	eps := math.Nextafter(1, 2) - 1
+	gamma, xi := 0.0, 0.0 // Максимумы модулей диагональных и внедиагональных элементов
+	for i := 0; i < n; i++ {
+		gamma = math.Max(gamma, math.Abs(h[i][i]))
+		for j := 0; j < i; j++ {
+			xi = math.Max(xi, math.Abs(h[i][j]))
+		}
+	}
+	betaSq := math.Max(gamma, eps)
+	if n > 1 {
+		betaSq = math.Max(betaSq, xi/math.Sqrt(float64(n*n-1)))
+	}
+	delta := minCurvature * math.Max(gamma+xi, 1)
+
+	l := IdentityMatrix(n)
+	d := make([]float64, n)
+	c := h.Copy() // c[i][j] - элементы L·D, вычисляемые по столбцам
+	maxE := 0.0
+	for j := 0; j < n; j++ {
+		for s := 0; s < j; s++ {
+			c[j][j] -= d[s] * l[j][s] * l[j][s]
+		}
+		theta := 0.0
+		for i := j + 1; i < n; i++ {
+			for s := 0; s < j; s++ {
+				c[i][j] -= d[s] * l[i][s] * l[j][s]
+			}
+			theta = math.Max(theta, math.Abs(c[i][j]))
+		}
+		d[j] = math.Max(math.Max(math.Abs(c[j][j]), theta*theta/betaSq), delta)
+		maxE = math.Max(maxE, d[j]-c[j][j])
+		for i := j + 1; i < n; i++ {
+			l[i][j] = c[i][j] / d[j]
+		}
+	}
+	return &LDLT{L: l, D: d, norm1: h.Norm1()}, maxE
+}
+
+// eigenvalueFlip заменяет собственные значения λ Гессиана на max(|λ|, δ),
+// где δ = minCurvature·max(1, max|λ|), и решает систему в собственном базисе.
+func eigenvalueFlip(h Matrix) (func([]float64) []float64, float64) {
+	values, vectors := SymmetricEigen(h)
+	maxAbs := 0.0
+	for _, v := range values {
+		maxAbs = math.Max(maxAbs, math.Abs(v))
+	}
+	delta := minCurvature * math.Max(1, maxAbs)
+	modified := make([]float64, len(values))
+	shift := 0.0
+	for i, v := range values {
+		modified[i] = math.Max(math.Abs(v), delta)
+		shift = math.Max(shift, modified[i]-v)
+	}
+	solve := func(b []float64) []float64 {
+		// x = Q diag(1/λ̃) Qᵀ b
+		n := len(b)
+		x := make([]float64, n)
+		for j := 0; j < n; j++ {
+			coef := 0.0
+			for i := 0; i < n; i++ {
+				coef += vectors[i][j] * b[i]
+			}
+			coef /= modified[j]
+			for i := 0; i < n; i++ {
+				x[i] += coef * vectors[i][j]
+			}
+		}
+		return x
+	}
+	return solve, shift
+}
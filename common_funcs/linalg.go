@@ -0,0 +1,485 @@
+package common_funcs
+
+import "math"
+
+// --- Разложения матриц и решение линейных систем произвольной размерности ---
+
+// Copy возвращает копию матрицы.
+func (m Matrix) Copy() Matrix {
+	res := make(Matrix, len(m))
+	for i := range m {
+		res[i] = make([]float64, len(m[i]))
+		copy(res[i], m[i])
+	}
+	return res
+}
+
+// Norm1 вычисляет 1-норму матрицы (максимальная сумма модулей по столбцам).
+func (m Matrix) Norm1() float64 {
+	norm := 0.0
+	for j := range m[0] {
+		sum := 0.0
+		for i := range m {
+			sum += math.Abs(m[i][j])
+		}
+		norm = math.Max(norm, sum)
+	}
+	return norm
+}
+
+// IsFinite сообщает, что все элементы матрицы конечны (нет NaN и ±Inf).
+func (m Matrix) IsFinite() bool {
+	for i := range m {
+		for _, v := range m[i] {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkSquare паникует, если матрица не квадратная.
+func (m Matrix) checkSquare(op string) {
+	for i := range m {
+		if len(m[i]) != len(m) {
+			panic(op + " работает только для квадратных матриц")
+		}
+	}
+}
+
+// LU - разложение PA = LU с частичным выбором ведущего элемента.
+type LU struct {
+	LU    Matrix // L (ниже диагонали, единицы на диагонали подразумеваются) и U (диагональ и выше)
+	Pivot []int  // Строка i матрицы PA - это строка Pivot[i] матрицы A
+	sign  float64
+	norm1 float64 // ‖A‖₁ для оценки числа обусловленности
+}
+
+// LU вычисляет LU-разложение с частичным выбором ведущего элемента.
+// Возвращает false, если матрица вырождена (нулевой ведущий элемент).
+func (m Matrix) LU() (*LU, bool) {
+	m.checkSquare("LU-разложение")
+	n := len(m)
+	a := m.Copy()
+	f := &LU{LU: a, Pivot: make([]int, n), sign: 1, norm1: m.Norm1()}
+	for i := range f.Pivot {
+		f.Pivot[i] = i
+	}
+	for k := 0; k < n; k++ {
+		// Выбираем ведущий элемент - наибольший по модулю в столбце k
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		if a[p][k] == 0 {
+			return f, false
+		}
+		if p != k {
+			a[p], a[k] = a[k], a[p]
+			f.Pivot[p], f.Pivot[k] = f.Pivot[k], f.Pivot[p]
+			f.sign = -f.sign
+		}
+		for i := k + 1; i < n; i++ {
+			a[i][k] /= a[k][k]
+			for j := k + 1; j < n; j++ {
+				a[i][j] -= a[i][k] * a[k][j]
+			}
+		}
+	}
+	return f, true
+}
+
+// Solve решает систему A x = b.
+func (f *LU) Solve(b []float64) []float64 {
+	n := len(f.LU)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = b[f.Pivot[i]]
+	}
+	// Прямой ход: L y = Pb
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= f.LU[i][j] * x[j]
+		}
+	}
+	// Обратный ход: U x = y
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= f.LU[i][j] * x[j]
+		}
+		x[i] /= f.LU[i][i]
+	}
+	return x
+}
+
+// SolveTranspose решает систему Aᵀ x = b.
+func (f *LU) SolveTranspose(b []float64) []float64 {
+	n := len(f.LU)
+	y := make([]float64, n)
+	copy(y, b)
+	// Uᵀ z = b
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			y[i] -= f.LU[j][i] * y[j]
+		}
+		y[i] /= f.LU[i][i]
+	}
+	// Lᵀ w = z
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			y[i] -= f.LU[j][i] * y[j]
+		}
+	}
+	// x = Pᵀ w
+	x := make([]float64, n)
+	for i := range y {
+		x[f.Pivot[i]] = y[i]
+	}
+	return x
+}
+
+// Det вычисляет определитель исходной матрицы.
+func (f *LU) Det() float64 {
+	det := f.sign
+	for i := range f.LU {
+		det *= f.LU[i][i]
+	}
+	return det
+}
+
+// Cond оценивает число обусловленности κ₁(A) = ‖A‖₁·‖A⁻¹‖₁ без обращения матрицы.
+func (f *LU) Cond() float64 {
+	return f.norm1 * estimateInvNorm1(len(f.LU), f.Solve, f.SolveTranspose)
+}
+
+// estimateInvNorm1 оценивает ‖A⁻¹‖₁ методом Хагера (Хайэма) по нескольким решениям
+// систем с A и Aᵀ. Оценка снизу, но на практике обычно точна до множителя 3.
+func estimateInvNorm1(n int, solve, solveT func([]float64) []float64) float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1 / float64(n)
+	}
+	est := 0.0
+	for iter := 0; iter < 5; iter++ {
+		y := solve(x)
+		est = 0
+		for _, v := range y {
+			est += math.Abs(v)
+		}
+		xi := make([]float64, n)
+		for i, v := range y {
+			xi[i] = 1
+			if v < 0 {
+				xi[i] = -1
+			}
+		}
+		z := solveT(xi)
+		j := 0
+		for i := range z {
+			if math.Abs(z[i]) > math.Abs(z[j]) {
+				j = i
+			}
+		}
+		if math.Abs(z[j]) <= DotProduct(z, x) {
+			break
+		}
+		x = make([]float64, n)
+		x[j] = 1
+	}
+	if math.IsNaN(est) {
+		return math.Inf(1)
+	}
+	return est
+}
+
+// Cholesky - разложение A = L Lᵀ симметричной положительно определенной матрицы.
+type Cholesky struct {
+	L     Matrix // Нижнетреугольный множитель
+	norm1 float64
+}
+
+// Cholesky вычисляет разложение Холецкого.
+// Возвращает false, если матрица не является положительно определенной.
+func (m Matrix) Cholesky() (*Cholesky, bool) {
+	m.checkSquare("Разложение Холецкого")
+	n := len(m)
+	l := NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		d := m[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, false
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := m[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+	return &Cholesky{L: l, norm1: m.Norm1()}, true
+}
+
+// IncompleteCholesky вычисляет неполное разложение Холецкого IC(0): множитель L
+// имеет нули там же, где нижний треугольник A, поэтому L Lᵀ ≈ A лишь приближенно
+// (для плотной матрицы совпадает с обычным разложением). Используется как
+// предобусловливатель. Возвращает false при неположительном диагональном элементе.
+func (m Matrix) IncompleteCholesky() (*Cholesky, bool) {
+	m.checkSquare("Неполное разложение Холецкого")
+	n := len(m)
+	l := NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		d := m[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0 || math.IsNaN(d) {
+			return nil, false
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			if m[i][j] == 0 {
+				continue // Вне портрета A элемент L остается нулевым
+			}
+			s := m[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+	return &Cholesky{L: l, norm1: m.Norm1()}, true
+}
+
+// Solve решает систему A x = b.
+func (c *Cholesky) Solve(b []float64) []float64 {
+	n := len(c.L)
+	x := make([]float64, n)
+	copy(x, b)
+	// L y = b
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= c.L[i][j] * x[j]
+		}
+		x[i] /= c.L[i][i]
+	}
+	// Lᵀ x = y
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= c.L[j][i] * x[j]
+		}
+		x[i] /= c.L[i][i]
+	}
+	return x
+}
+
+// Cond оценивает число обусловленности κ₁(A).
+func (c *Cholesky) Cond() float64 {
+	if c.norm1 < 0 {
+		// После модификаций ранга 1 норма A пересчитывается по множителю
+		c.norm1 = c.Matrix().Norm1()
+	}
+	return c.norm1 * estimateInvNorm1(len(c.L), c.Solve, c.Solve)
+}
+
+// Matrix восстанавливает матрицу A = L Lᵀ.
+func (c *Cholesky) Matrix() Matrix {
+	n := len(c.L)
+	a := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			s := 0.0
+			for k := 0; k <= j; k++ {
+				s += c.L[i][k] * c.L[j][k]
+			}
+			a[i][j], a[j][i] = s, s
+		}
+	}
+	return a
+}
+
+// Update пересчитывает разложение для A + v vᵀ за O(n²) операций
+// (вращениями, без повторного разложения матрицы).
+func (c *Cholesky) Update(v []float64) {
+	c.rankOne(v, 1)
+}
+
+// Downdate пересчитывает разложение для A - v vᵀ за O(n²) операций.
+// Возвращает false и оставляет разложение без изменений, если A - v vᵀ
+// не является положительно определенной.
+func (c *Cholesky) Downdate(v []float64) bool {
+	return c.rankOne(v, -1)
+}
+
+// rankOne выполняет модификацию A + sign·v vᵀ над копией множителя L.
+func (c *Cholesky) rankOne(v []float64, sign float64) bool {
+	n := len(c.L)
+	l := c.L.Copy()
+	x := make([]float64, n)
+	copy(x, v)
+	for k := 0; k < n; k++ {
+		d := l[k][k]*l[k][k] + sign*x[k]*x[k]
+		if d <= 0 || math.IsNaN(d) {
+			return false
+		}
+		r := math.Sqrt(d)
+		cs, sn := r/l[k][k], x[k]/l[k][k]
+		l[k][k] = r
+		for i := k + 1; i < n; i++ {
+			l[i][k] = (l[i][k] + sign*sn*x[i]) / cs
+			x[i] = cs*x[i] - sn*l[i][k]
+		}
+	}
+	c.L = l
+	c.norm1 = -1
+	return true
+}
+
+// LDLT - разложение A = L D Lᵀ симметричной матрицы (без выбора ведущего элемента).
+// В отличие от Холецкого не требует положительной определенности: знаки D
+// дают инерцию матрицы (число положительных и отрицательных собственных значений).
+type LDLT struct {
+	L     Matrix    // Нижнетреугольный множитель с единичной диагональю
+	D     []float64 // Диагональ D
+	norm1 float64
+}
+
+// LDLT вычисляет LDLᵀ-разложение.
+// Возвращает false, если встретился нулевой диагональный элемент D.
+func (m Matrix) LDLT() (*LDLT, bool) {
+	m.checkSquare("LDLᵀ-разложение")
+	n := len(m)
+	l := IdentityMatrix(n)
+	d := make([]float64, n)
+	for j := 0; j < n; j++ {
+		d[j] = m[j][j]
+		for k := 0; k < j; k++ {
+			d[j] -= l[j][k] * l[j][k] * d[k]
+		}
+		if d[j] == 0 || math.IsNaN(d[j]) {
+			return nil, false
+		}
+		for i := j + 1; i < n; i++ {
+			s := m[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k] * d[k]
+			}
+			l[i][j] = s / d[j]
+		}
+	}
+	return &LDLT{L: l, D: d, norm1: m.Norm1()}, true
+}
+
+// Solve решает систему A x = b.
+func (f *LDLT) Solve(b []float64) []float64 {
+	n := len(f.L)
+	x := make([]float64, n)
+	copy(x, b)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= f.L[i][j] * x[j]
+		}
+	}
+	for i := range x {
+		x[i] /= f.D[i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= f.L[j][i] * x[j]
+		}
+	}
+	return x
+}
+
+// Inertia возвращает количество положительных и отрицательных элементов D,
+// равное числу положительных и отрицательных собственных значений A.
+func (f *LDLT) Inertia() (pos, neg int) {
+	for _, d := range f.D {
+		if d > 0 {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	return pos, neg
+}
+
+// Cond оценивает число обусловленности κ₁(A).
+func (f *LDLT) Cond() float64 {
+	return f.norm1 * estimateInvNorm1(len(f.L), f.Solve, f.Solve)
+}
+
+// SolveLinear решает систему A x = b через LU-разложение и возвращает решение
+// вместе с оценкой числа обусловленности. ok == false для вырожденной матрицы.
+func SolveLinear(a Matrix, b []float64) (x []float64, cond float64, ok bool) {
+	f, ok := a.LU()
+	if !ok {
+		return nil, math.Inf(1), false
+	}
+	return f.Solve(b), f.Cond(), true
+}
+
+// SymmetricEigen вычисляет собственные значения и векторы симметричной матрицы
+// методом вращений Якоби. Столбец j матрицы vectors соответствует values[j].
+func SymmetricEigen(m Matrix) (values []float64, vectors Matrix) {
+	m.checkSquare("SymmetricEigen")
+	n := len(m)
+	a := m.Copy()
+	v := IdentityMatrix(n)
+	for sweep := 0; sweep < 100; sweep++ {
+		off, total := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				total += a[i][j] * a[i][j]
+				if i != j {
+					off += a[i][j] * a[i][j]
+				}
+			}
+		}
+		if off <= 1e-30*total || off == 0 {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				// Угол вращения, зануляющего a[p][q]
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	values = make([]float64, n)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return values, v
+}
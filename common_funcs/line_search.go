@@ -0,0 +1,382 @@
+package common_funcs
+
+import (
+	"fmt"
+	"math"
+	"optimizationMethodsTask4/one_dim"
+	"strconv"
+	"strings"
+)
+
+// LineSearch - правило выбора шага alpha вдоль направления спуска direction
+// из точки x. grad - градиент в x: он уже вычислен решателем, поэтому неточным
+// правилам (Армихо) не нужно тратить на него лишнее вычисление.
+type LineSearch interface {
+	Step(obj Objective, x, direction, grad []float64) float64
+	String() string
+}
+
+// GoldenSection - точный одномерный поиск золотым сечением на [0, MaxAlpha].
+type GoldenSection struct {
+	MaxAlpha float64 // Верхняя граница для шага alpha
+	Tol      float64 // Точность по alpha
+}
+
+func (ls GoldenSection) Step(obj Objective, x, direction, grad []float64) float64 {
+	return GoldenSectionSearch(obj, x, direction, 0.0, ls.MaxAlpha, ls.Tol)
+}
+
+func (ls GoldenSection) String() string {
+	return "золотое сечение"
+}
+
+// Interval - точный одномерный поиск любым методом пакета one_dim на [0, MaxAlpha].
+type Interval struct {
+	Method   one_dim.Method // Метод одномерной минимизации
+	MaxAlpha float64        // Верхняя граница для шага alpha
+	Tol      float64        // Точность по alpha
+}
+
+func (ls Interval) Step(obj Objective, x, direction, grad []float64) float64 {
+	return ls.Method.Minimize(rayFunction(obj, x, direction), 0, ls.MaxAlpha, ls.Tol).X
+}
+
+func (ls Interval) String() string {
+	return ls.Method.Name
+}
+
+// rayFunction возвращает φ(α) = f(x + α·direction).
+func rayFunction(obj Objective, x, direction []float64) func(float64) float64 {
+	return func(alpha float64) float64 {
+		return obj.Value(VectorAdd(x, ScalarMult(alpha, direction)))
+	}
+}
+
+// Bracketed - точный одномерный поиск без фиксированной верхней границы шага:
+// сначала методом Свенна с начальным шагом InitialStep находится отрезок,
+// содержащий минимум, затем он уточняется методом Method (по умолчанию -
+// золотым сечением) с точностью Tol. В отличие от GoldenSection шаги длиннее 1
+// возможны, а минимум за пределами [0, MaxAlpha] не обрезается.
+type Bracketed struct {
+	Method      one_dim.Method // Метод уточнения (нулевое значение - золотое сечение)
+	InitialStep float64        // Начальный шаг h метода Свенна
+	Tol         float64        // Точность по alpha
+	MaxSteps    int            // Ограничение числа удвоений шага
+}
+
+// DefaultBracketed - параметры поиска с предварительной локализацией минимума.
+var DefaultBracketed = Bracketed{Method: one_dim.Golden, InitialStep: 0.1, Tol: 1e-6, MaxSteps: 60}
+
+func (ls Bracketed) Step(obj Objective, x, direction, grad []float64) float64 {
+	phi := rayFunction(obj, x, direction)
+	a, b, _ := one_dim.Swann(phi, ls.InitialStep, ls.MaxSteps)
+	return ls.method().Minimize(phi, a, b, ls.Tol).X
+}
+
+func (ls Bracketed) method() one_dim.Method {
+	if ls.Method.Minimize == nil {
+		return one_dim.Golden
+	}
+	return ls.Method
+}
+
+func (ls Bracketed) String() string {
+	return "метод Свенна + " + ls.method().Name
+}
+
+// Armijo - неточный поиск дроблением шага: начиная с InitialStep, шаг уменьшается
+// до выполнения условия достаточного убывания f(x + αd) ≤ f(x) + C1·α·gᵀd.
+// Новый шаг берется из минимума квадратичной (на первом дроблении) или кубической
+// интерполяции φ(α) = f(x + αd) и ограничивается отрезком [0.1α, 0.5α].
+// Обычно хватает 1-3 вычислений функции вместо ~30 у золотого сечения.
+type Armijo struct {
+	C1          float64 // Параметр условия достаточного убывания (0 < C1 < 1)
+	InitialStep float64 // Пробный шаг (1 - естественный шаг Ньютона и квазиньютоновских методов)
+	MinStep     float64 // Шаг, меньше которого поиск прекращается с alpha = 0
+}
+
+// DefaultArmijo - параметры поиска Армихо, принятые в большинстве реализаций.
+var DefaultArmijo = Armijo{C1: 1e-4, InitialStep: 1, MinStep: 1e-12}
+
+func (ls Armijo) Step(obj Objective, x, direction, grad []float64) float64 {
+	slope := DotProduct(grad, direction) // φ'(0)
+	if slope >= 0 {
+		return 0 // Не направление спуска: уменьшить функцию нельзя
+	}
+	f0 := obj.Value(x)
+	alpha := ls.InitialStep
+	fAlpha := obj.Value(VectorAdd(x, ScalarMult(alpha, direction)))
+	prevAlpha, prevF := 0.0, 0.0
+
+	// Отрицание условия, чтобы NaN и бесконечность тоже приводили к дроблению
+	for !(fAlpha <= f0+ls.C1*alpha*slope) {
+		if alpha < ls.MinStep {
+			return 0
+		}
+		var next float64
+		if prevAlpha == 0 {
+			// Минимум параболы по φ(0), φ'(0) и φ(α)
+			next = -slope * alpha * alpha / (2 * (fAlpha - f0 - slope*alpha))
+		} else {
+			next = cubicStep(f0, slope, prevAlpha, prevF, alpha, fAlpha)
+		}
+		if math.IsNaN(next) || math.IsInf(next, 0) {
+			next = 0.5 * alpha
+		}
+		next = math.Max(0.1*alpha, math.Min(next, 0.5*alpha))
+		prevAlpha, prevF = alpha, fAlpha
+		alpha = next
+		fAlpha = obj.Value(VectorAdd(x, ScalarMult(alpha, direction)))
+	}
+	return alpha
+}
+
+// cubicStep - минимум кубического многочлена, проходящего через φ(0) = f0, φ'(0) = slope,
+// φ(a0) = f0a и φ(a1) = f1a (Нокедал, Райт, (3.58)).
+func cubicStep(f0, slope, a0, f0a, a1, f1a float64) float64 {
+	d0 := f0a - f0 - slope*a0
+	d1 := f1a - f0 - slope*a1
+	den := a0 * a0 * a1 * a1 * (a1 - a0)
+	a := (a0*a0*d1 - a1*a1*d0) / den
+	b := (-a0*a0*a0*d1 + a1*a1*a1*d0) / den
+	if a == 0 {
+		return -slope / (2 * b)
+	}
+	return (-b + math.Sqrt(b*b-3*a*slope)) / (3 * a)
+}
+
+func (ls Armijo) String() string {
+	return "Армихо"
+}
+
+// StrongWolfe - неточный поиск, обеспечивающий сильные условия Вольфе:
+// f(x + αd) ≤ f(x) + C1·α·gᵀd и |∇f(x + αd)ᵀd| ≤ C2·|gᵀd|, 0 < C1 < C2 < 1.
+// Реализованы алгоритмы 3.5 и 3.6 (zoom) из книги Нокедала и Райта: шаг
+// увеличивается, пока не найден отрезок с искомой точкой, затем отрезок сужается
+// кубической интерполяцией по значениям и производным φ(α) = f(x + αd).
+// Для метода Флетчера-Ривза направление гарантированно будет направлением спуска
+// только при C2 < 1/2 (обычно берут 0.1), для ньютоновских методов - C2 = 0.9.
+type StrongWolfe struct {
+	C1, C2      float64 // Параметры условий Вольфе
+	InitialStep float64 // Пробный шаг
+	MaxStep     float64 // Наибольший допустимый шаг
+	MaxIter     int     // Ограничение числа пробных шагов в каждой фазе
+}
+
+// DefaultWolfe - параметры для методов Ньютона и квазиньютоновских методов.
+var DefaultWolfe = StrongWolfe{C1: 1e-4, C2: 0.9, InitialStep: 1, MaxStep: 1e6, MaxIter: 30}
+
+// DefaultWolfeCG - параметры для нелинейных сопряженных градиентов (C2 = 0.1).
+var DefaultWolfeCG = StrongWolfe{C1: 1e-4, C2: 0.1, InitialStep: 1, MaxStep: 1e6, MaxIter: 30}
+
+func (ls StrongWolfe) Step(obj Objective, x, direction, grad []float64) float64 {
+	slope0 := DotProduct(grad, direction) // φ'(0)
+	if slope0 >= 0 {
+		return 0 // Не направление спуска
+	}
+	f0 := obj.Value(x)
+	// φ(α) и φ'(α) = ∇f(x + αd)ᵀd
+	phi := func(alpha float64) (float64, float64) {
+		p := VectorAdd(x, ScalarMult(alpha, direction))
+		return obj.Value(p), DotProduct(obj.Gradient(p), direction)
+	}
+	sufficient := func(alpha, f float64) bool {
+		return f <= f0+ls.C1*alpha*slope0
+	}
+	curvature := func(df float64) bool {
+		return math.Abs(df) <= -ls.C2*slope0
+	}
+
+	// zoom сужает отрезок между lo (лучшая точка с достаточным убыванием) и hi
+	zoom := func(lo, fLo, dLo, hi, fHi, dHi float64) float64 {
+		for j := 0; j < ls.MaxIter; j++ {
+			alpha := cubicInterpolate(lo, fLo, dLo, hi, fHi, dHi)
+			f, df := phi(alpha)
+			if !sufficient(alpha, f) || f >= fLo {
+				hi, fHi, dHi = alpha, f, df
+				continue
+			}
+			if curvature(df) {
+				return alpha
+			}
+			if df*(hi-lo) >= 0 {
+				hi, fHi, dHi = lo, fLo, dLo
+			}
+			lo, fLo, dLo = alpha, f, df
+		}
+		if lo > 0 {
+			return lo // Лучшая найденная точка с достаточным убыванием
+		}
+		// Ни одна пробная точка не дала достаточного убывания: нулевой шаг оставил бы
+		// метод в той же точке, поэтому дробим шаг от hi, как в поиске Армихо
+		return Armijo{C1: ls.C1, InitialStep: hi, MinStep: DefaultArmijo.MinStep}.Step(obj, x, direction, grad)
+	}
+
+	prev, fPrev, dPrev := 0.0, f0, slope0
+	alpha := ls.InitialStep
+	for i := 0; i < ls.MaxIter; i++ {
+		f, df := phi(alpha)
+		if !sufficient(alpha, f) || (i > 0 && f >= fPrev) {
+			return zoom(prev, fPrev, dPrev, alpha, f, df)
+		}
+		if curvature(df) {
+			return alpha
+		}
+		if df >= 0 {
+			return zoom(alpha, f, df, prev, fPrev, dPrev)
+		}
+		if alpha >= ls.MaxStep {
+			return alpha
+		}
+		prev, fPrev, dPrev = alpha, f, df
+		alpha = math.Min(2*alpha, ls.MaxStep)
+	}
+	return prev
+}
+
+// cubicInterpolate - минимум кубического многочлена по значениям и производным
+// в точках a и b (Нокедал, Райт, (3.59)), ограниченный внутренней частью отрезка;
+// если минимум не определен, возвращается середина отрезка.
+func cubicInterpolate(a, fa, da, b, fb, db float64) float64 {
+	lo, hi := math.Min(a, b), math.Max(a, b)
+	d1 := da + db - 3*(fa-fb)/(a-b)
+	disc := d1*d1 - da*db
+	mid := (a + b) / 2
+	if disc < 0 || math.IsNaN(disc) {
+		return mid
+	}
+	d2 := math.Copysign(math.Sqrt(disc), b-a)
+	t := b - (b-a)*(db+d2-d1)/(db-da+2*d2)
+	margin := 0.1 * (hi - lo)
+	if math.IsNaN(t) || t < lo+margin || t > hi-margin {
+		return mid
+	}
+	return t
+}
+
+func (ls StrongWolfe) String() string {
+	return fmt.Sprintf("сильные условия Вольфе, c1 = %g, c2 = %g", ls.C1, ls.C2)
+}
+
+// LineSearchUsage перечисляет имена, которые принимает LineSearchByName,
+// для справки флагов -linesearch.
+var LineSearchUsage = strings.Join(one_dim.MethodNames(), ", ") + ", swann, swann+<метод>, armijo, wolfe, wolfe-cg, wolfe:c1:c2"
+
+// LineSearchByName возвращает одномерный поиск по имени для флагов командной строки:
+// "golden" - золотое сечение на [0, 1] с точностью 1e-6, "fibonacci", "dichotomy",
+// "parabolic", "brent" - соответствующий метод one_dim на [0, 1] с той же точностью,
+// "swann" - DefaultBracketed, "swann+<метод>" - метод Свенна с уточнением методом
+// one_dim, "armijo" - DefaultArmijo, "wolfe" - DefaultWolfe, "wolfe-cg" - DefaultWolfeCG.
+// Параметры условий Вольфе можно задать явно: "wolfe:1e-4:0.1" (c1 и c2).
+func LineSearchByName(name string) (LineSearch, error) {
+	switch name {
+	case "golden":
+		return GoldenSection{MaxAlpha: 1.0, Tol: 1e-6}, nil
+	case "swann":
+		return DefaultBracketed, nil
+	case "armijo":
+		return DefaultArmijo, nil
+	case "wolfe":
+		return DefaultWolfe, nil
+	case "wolfe-cg":
+		return DefaultWolfeCG, nil
+	}
+	if method, ok := one_dim.MethodByName(name); ok {
+		return Interval{Method: method, MaxAlpha: 1.0, Tol: 1e-6}, nil
+	}
+	if methodName, ok := strings.CutPrefix(name, "swann+"); ok {
+		method, ok := one_dim.MethodByName(methodName)
+		if !ok {
+			return nil, fmt.Errorf("неизвестный одномерный метод %q (допустимо: %s)", methodName, strings.Join(one_dim.MethodNames(), ", "))
+		}
+		ls := DefaultBracketed
+		ls.Method = method
+		return ls, nil
+	}
+	if params, ok := strings.CutPrefix(name, "wolfe:"); ok {
+		c1Str, c2Str, _ := strings.Cut(params, ":")
+		c1, err1 := strconv.ParseFloat(c1Str, 64)
+		c2, err2 := strconv.ParseFloat(c2Str, 64)
+		if err1 != nil || err2 != nil || c1 <= 0 || c1 >= c2 || c2 >= 1 {
+			return nil, fmt.Errorf("некорректные параметры условий Вольфе %q: нужно 0 < c1 < c2 < 1", params)
+		}
+		ls := DefaultWolfe
+		ls.C1, ls.C2 = c1, c2
+		return ls, nil
+	}
+	return nil, fmt.Errorf("неизвестный одномерный поиск %q (допустимо: %s)", name, LineSearchUsage)
+}
+
+// ParseLineSearches разбирает список имен через запятую, например "golden,armijo".
+func ParseLineSearches(list string) ([]LineSearch, error) {
+	var res []LineSearch
+	for _, name := range strings.Split(list, ",") {
+		ls, err := LineSearchByName(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ls)
+	}
+	return res, nil
+}
+
+// CountingObjective считает вычисления функции, градиента и Гессиана,
+// чтобы сравнивать стоимость методов и одномерных поисков.
+type CountingObjective struct {
+	HessianObjective
+	Values, Gradients, Hessians int
+}
+
+// NewCountingObjective оборачивает obj счетчиками вычислений.
+func NewCountingObjective(obj HessianObjective) *CountingObjective {
+	return &CountingObjective{HessianObjective: obj}
+}
+
+func (o *CountingObjective) Value(x []float64) float64 {
+	o.Values++
+	return o.HessianObjective.Value(x)
+}
+
+func (o *CountingObjective) Gradient(x []float64) []float64 {
+	o.Gradients++
+	return o.HessianObjective.Gradient(x)
+}
+
+func (o *CountingObjective) Hessian(x []float64) Matrix {
+	o.Hessians++
+	return o.HessianObjective.Hessian(x)
+}
+
+// String описывает количество вычислений для вывода результатов.
+func (o *CountingObjective) String() string {
+	return fmt.Sprintf("вычислений f: %d, градиента: %d, Гессиана: %d", o.Values, o.Gradients, o.Hessians)
+}
+
+// CostBaseline хранит стоимость запусков с золотым сечением по названиям
+// вариантов метода, чтобы сообщать экономию вычислений при других одномерных поисках.
+type CostBaseline map[string]*CountingObjective
+
+// Compare запоминает стоимость counted, если lineSearch - золотое сечение, и иначе
+// сравнивает ее с запомненной для того же варианта метода. Возвращает "", если
+// сравнивать не с чем (золотое сечение должно идти в списке поисков раньше).
+func (b CostBaseline) Compare(method string, lineSearch LineSearch, counted *CountingObjective) string {
+	if _, ok := lineSearch.(GoldenSection); ok {
+		b[method] = counted
+		return ""
+	}
+	base, ok := b[method]
+	if !ok {
+		return ""
+	}
+	change := func(name string, was, now int) string {
+		if was == 0 {
+			return fmt.Sprintf("%s: %d → %d", name, was, now)
+		}
+		return fmt.Sprintf("%s: %d → %d (%+.0f%%)", name, was, now, 100*float64(now-was)/float64(was))
+	}
+	res := change("вычислений f", base.Values, counted.Values) + ", " + change("градиента", base.Gradients, counted.Gradients)
+	if base.Hessians > 0 || counted.Hessians > 0 {
+		res += ", " + change("Гессиана", base.Hessians, counted.Hessians)
+	}
+	return res
+}
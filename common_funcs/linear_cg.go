@@ -0,0 +1,182 @@
+package common_funcs
+
+import "math"
+
+// LinearOperator вычисляет произведение A·v. Матрица A может не храниться
+// явно (матрично-свободный оператор), важна лишь ее симметричность.
+type LinearOperator func(v []float64) []float64
+
+// Operator возвращает матрицу как линейный оператор.
+func (m Matrix) Operator() LinearOperator {
+	return func(v []float64) []float64 {
+		return MatrixVectorMult(m, v)
+	}
+}
+
+// LinearCGResult - результат линейного метода сопряженных градиентов.
+type LinearCGResult struct {
+	X         []float64 // Найденное решение
+	Iter      int       // Количество итераций (произведений A·p)
+	Residuals []float64 // Нормы невязок ‖b - A x_k‖, k = 0..Iter
+	Converged bool      // Достигнута ли точность tol
+	Curvature bool      // Остановка из-за pᵀAp ≤ 0 (A не положительно определена)
+}
+
+// CGIteration - итерации линейного метода сопряженных градиентов для A x = b
+// (с предобусловливанием M): общая рекуррентная часть LinearCG и усеченного
+// метода Штайхауга-Тоинта, которые различаются только правилами остановки.
+// Шаг итерации: Curvature (вычисление A·p), затем Advance.
+type CGIteration struct {
+	X  []float64 // Текущее приближение
+	R  []float64 // Невязка b - A x
+	P  []float64 // Текущее направление
+	AP []float64 // A·P, вычисленное в Curvature
+
+	a       LinearOperator
+	precond func([]float64) []float64
+	rz      float64 // rᵀz, z = M⁻¹r
+	pAp     float64 // pᵀAp
+}
+
+// NewCGIteration начинает итерации из x0. precond вычисляет z = M⁻¹r
+// (nil - без предобусловливания, M = I).
+func NewCGIteration(a LinearOperator, b, x0 []float64, precond func([]float64) []float64) *CGIteration {
+	if precond == nil {
+		precond = func(r []float64) []float64 { return append([]float64(nil), r...) }
+	}
+	it := &CGIteration{X: append([]float64(nil), x0...), a: a, precond: precond}
+	it.R = VectorSub(b, a(it.X))
+	z := precond(it.R)
+	it.P = append([]float64(nil), z...)
+	it.rz = DotProduct(it.R, z)
+	return it
+}
+
+// ResidualNorm возвращает ‖r‖ = ‖b - A x‖.
+func (it *CGIteration) ResidualNorm() float64 {
+	return VectorNorm(it.R)
+}
+
+// Curvature вычисляет A·p и возвращает кривизну pᵀAp вдоль текущего направления.
+func (it *CGIteration) Curvature() float64 {
+	it.AP = it.a(it.P)
+	it.pAp = DotProduct(it.P, it.AP)
+	return it.pAp
+}
+
+// Alpha возвращает точный шаг rᵀz / pᵀAp - минимум ½xᵀAx - bᵀx вдоль p
+// (вызывается после Curvature).
+func (it *CGIteration) Alpha() float64 {
+	return it.rz / it.pAp
+}
+
+// Advance делает шаг x += alpha·p, r -= alpha·A·p и строит следующее
+// направление p = z + beta·p, A-сопряженное всем предыдущим (beta = r₊ᵀz₊ / rᵀz).
+func (it *CGIteration) Advance() {
+	alpha := it.Alpha()
+	for i := range it.X {
+		it.X[i] += alpha * it.P[i]
+		it.R[i] -= alpha * it.AP[i]
+	}
+	z := it.precond(it.R)
+	rzNext := DotProduct(it.R, z)
+	beta := rzNext / it.rz
+	for i := range it.P {
+		it.P[i] = z[i] + beta*it.P[i]
+	}
+	it.rz = rzNext
+}
+
+// LinearCG решает систему A x = b с симметричной положительно определенной A,
+// что эквивалентно минимизации квадратичной функции ½xᵀAx - bᵀx. Шаг вдоль
+// направления вычисляется в явном виде, alpha = rᵀz / pᵀAp, поэтому одномерный
+// поиск не нужен, а в точной арифметике метод сходится не более чем за n шагов.
+// precond вычисляет z = M⁻¹r (nil - без предобусловливания, M = I).
+// Останавливается при ‖r‖ < tol или после maxIter итераций.
+func LinearCG(a LinearOperator, b, x0 []float64, tol float64, maxIter int, precond func([]float64) []float64) LinearCGResult {
+	it := NewCGIteration(a, b, x0, precond)
+	res := LinearCGResult{Residuals: []float64{it.ResidualNorm()}}
+
+	for res.Iter < maxIter {
+		if res.Residuals[res.Iter] < tol {
+			res.Converged = true
+			break
+		}
+		pAp := it.Curvature()
+		if pAp <= 0 || math.IsNaN(pAp) {
+			res.Curvature = true
+			break
+		}
+		it.Advance()
+		res.Iter++
+		res.Residuals = append(res.Residuals, it.ResidualNorm())
+	}
+	if !res.Converged && res.Residuals[res.Iter] < tol {
+		res.Converged = true
+	}
+	res.X = it.X
+	return res
+}
+
+// quadraticTol - относительная точность проверки квадратичности функции.
+const quadraticTol = 1e-8
+
+// QuadraticForm проверяет, является ли obj квадратичной функцией
+// f(x) = ½xᵀAx - bᵀx + c, и возвращает A и b. A = ∇²f(0), b = -∇f(0);
+// затем значение, градиент и Гессиан сравниваются с моделью в нескольких
+// пробных точках. Проверка численная: функция, совпадающая с квадратичной
+// в пробных точках, будет принята за квадратичную.
+func QuadraticForm(obj HessianObjective) (Matrix, []float64, bool) {
+	n := obj.Dimension()
+	zero := make([]float64, n)
+	a := obj.Hessian(zero)
+	b := ScalarMult(-1.0, obj.Gradient(zero))
+	c := obj.Value(zero)
+
+	near := func(got, want, scale float64) bool {
+		return math.Abs(got-want) <= quadraticTol*math.Max(scale, 1)
+	}
+	scale := a.Norm1() + VectorNorm(b) + math.Abs(c)
+	for i := range a {
+		for j := range a {
+			if !near(a[i][j], a[j][i], scale) {
+				return nil, nil, false
+			}
+		}
+	}
+
+	// Пробные точки: вектор из единиц, знакочередующийся вектор и
+	// "неровный" вектор, чтобы задеть все смешанные слагаемые
+	points := make([][]float64, 3)
+	for k := range points {
+		points[k] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		points[0][i] = 1
+		points[1][i] = float64(1 - 2*(i%2))
+		points[2][i] = 0.5 + 0.37*float64((3*i+1)%7)
+	}
+	for _, p := range points {
+		ap := MatrixVectorMult(a, p)
+		model := 0.5*DotProduct(p, ap) - DotProduct(b, p) + c
+		pScale := scale * (1 + DotProduct(p, p))
+		if !near(obj.Value(p), model, pScale) {
+			return nil, nil, false
+		}
+		grad := obj.Gradient(p)
+		for i := range grad {
+			if !near(grad[i], ap[i]-b[i], pScale) {
+				return nil, nil, false
+			}
+		}
+		hess := obj.Hessian(p)
+		for i := range hess {
+			for j := range hess[i] {
+				if !near(hess[i][j], a[i][j], scale) {
+					return nil, nil, false
+				}
+			}
+		}
+	}
+	return a, b, true
+}
@@ -0,0 +1,97 @@
+package common_funcs
+
+import "fmt"
+
+// Objective описывает целевую функцию, которую минимизируют все методы.
+// Решатели и одномерный поиск получают её параметром, поэтому в одном
+// процессе можно запускать одни и те же методы на разных функциях.
+type Objective interface {
+	Value(x []float64) float64      // Значение f(x)
+	Gradient(x []float64) []float64 // Градиент ∇f(x)
+	Dimension() int                 // Размерность пространства
+}
+
+// HessianObjective - целевая функция, дополнительно умеющая вычислять матрицу Гессе.
+// Гессиан необязателен: методы, которым он нужен (Ньютон), требуют именно этот интерфейс.
+type HessianObjective interface {
+	Objective
+	Hessian(x []float64) Matrix // Матрица Гессе ∇²f(x)
+}
+
+// HessianVectorObjective - целевая функция, умеющая вычислять произведение Гессиана
+// на вектор без построения самой матрицы (нужно для задач большой размерности).
+type HessianVectorObjective interface {
+	Objective
+	HessianVector(x, v []float64) []float64 // Произведение ∇²f(x)·v
+}
+
+// funcObjective собирает Objective из отдельных функций.
+type funcObjective struct {
+	dim  int
+	f    func([]float64) float64
+	grad func([]float64) []float64
+}
+
+func (o funcObjective) Value(x []float64) float64 {
+	o.checkDim(x)
+	return o.f(x)
+}
+
+func (o funcObjective) Gradient(x []float64) []float64 {
+	o.checkDim(x)
+	return o.grad(x)
+}
+
+func (o funcObjective) Dimension() int {
+	return o.dim
+}
+
+// checkDim проверяет размерность входного вектора.
+func (o funcObjective) checkDim(x []float64) {
+	if len(x) != o.dim {
+		panic(fmt.Sprintf("Целевая функция ожидает %d-мерный вектор, получено: %d", o.dim, len(x)))
+	}
+}
+
+// funcHessObjective дополняет funcObjective вычислением Гессиана.
+type funcHessObjective struct {
+	funcObjective
+	hess func([]float64) Matrix
+}
+
+func (o funcHessObjective) Hessian(x []float64) Matrix {
+	o.checkDim(x)
+	return o.hess(x)
+}
+
+// NewObjective создает целевую функцию размерности dim из функции f и её градиента grad.
+func NewObjective(dim int, f func([]float64) float64, grad func([]float64) []float64) Objective {
+	return funcObjective{dim: dim, f: f, grad: grad}
+}
+
+// NewHessianObjective создает целевую функцию размерности dim с градиентом и Гессианом.
+func NewHessianObjective(dim int, f func([]float64) float64, grad func([]float64) []float64, hess func([]float64) Matrix) HessianObjective {
+	return funcHessObjective{funcObjective: funcObjective{dim: dim, f: f, grad: grad}, hess: hess}
+}
+
+// Task17164 - целевая функция варианта 17.164 (F, GradF и Hessian из этого пакета).
+var Task17164 = NewHessianObjective(3, F, GradF, Hessian)
+
+// hessianProduct дополняет функцию с явным Гессианом произведением Гессиана на вектор.
+type hessianProduct struct {
+	HessianObjective
+}
+
+func (o hessianProduct) HessianVector(x, v []float64) []float64 {
+	return MatrixVectorMult(o.Hessian(x), v)
+}
+
+// WithHessianVector возвращает obj как HessianVectorObjective. Если obj не умеет
+// умножать Гессиан на вектор сам, произведение вычисляется через полную матрицу
+// (годится для небольших задач вроде варианта 17.164).
+func WithHessianVector(obj HessianObjective) HessianVectorObjective {
+	if hv, ok := obj.(HessianVectorObjective); ok {
+		return hv
+	}
+	return hessianProduct{obj}
+}
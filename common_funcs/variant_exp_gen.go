@@ -0,0 +1,71 @@
+// Code generated by gen_objective; DO NOT EDIT.
+
+package common_funcs
+
+import (
+	"fmt"
+	"math"
+)
+
+// FExp вычисляет значение целевой функции.
+// f(x1, x2, x3) = x1^2 + 2*x2^2 + x1^2*x2^2 + 2*x3 + exp(x2^2 + x3^2) - x2
+func FExp(x []float64) float64 {
+	if len(x) != 3 {
+		panic(fmt.Sprintf("Функция FExp ожидает 3-мерный вектор, получено: %d", len(x)))
+	}
+	x1 := x[0]
+	x2 := x[1]
+	x3 := x[2]
+
+	// Разбиваем формулу на отдельные слагаемые для читаемости и отладки
+	term1 := math.Pow(x1, 2)                             // x1^2
+	term2 := 2 * math.Pow(x2, 2)                         // 2*x2^2
+	term3 := math.Pow(x1, 2) * math.Pow(x2, 2)           // x1^2*x2^2
+	term4 := 2 * x3                                      // 2*x3
+	term5 := math.Exp(math.Pow(x2, 2) + math.Pow(x3, 2)) // exp(x2^2 + x3^2)
+	term6 := -x2                                         // -x2
+
+	// Возвращаем сумму всех слагаемых
+	return term1 + term2 + term3 + term4 + term5 + term6
+}
+
+// GradFExp вычисляет градиент целевой функции в точке x.
+func GradFExp(x []float64) []float64 {
+	if len(x) != 3 {
+		panic(fmt.Sprintf("Функция GradFExp ожидает 3-мерный вектор, получено: %d", len(x)))
+	}
+	x1 := x[0]
+	x2 := x[1]
+	x3 := x[2]
+	grad := make([]float64, 3)
+	grad[0] = 2*x1 + 2*x1*math.Pow(x2, 2)                                                      // ∂f/∂x1
+	grad[1] = 4*x2 + 2*math.Pow(x1, 2)*x2 + 2*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2))*x2 - 1 // ∂f/∂x2
+	grad[2] = 2*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2))*x3 + 2                               // ∂f/∂x3
+	return grad
+}
+
+// HessianExp вычисляет матрицу Гессе целевой функции в точке x.
+func HessianExp(x []float64) Matrix {
+	if len(x) != 3 {
+		panic(fmt.Sprintf("Функция HessianExp ожидает 3-мерный вектор, получено: %d", len(x)))
+	}
+	x1 := x[0]
+	x2 := x[1]
+	x3 := x[2]
+	hess := NewMatrix(3, 3)
+
+	hess[0][0] = 2*math.Pow(x2, 2) + 2
+	hess[0][1] = 4 * x1 * x2
+	hess[0][2] = 0
+	hess[1][0] = hess[0][1] // Симметричная
+	hess[1][1] = 2*math.Pow(x1, 2) + 4*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2))*math.Pow(x2, 2) + 2*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2)) + 4
+	hess[1][2] = 4 * math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2)) * x3 * x2
+	hess[2][0] = hess[0][2] // Симметричная
+	hess[2][1] = hess[1][2] // Симметричная
+	hess[2][2] = 4*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2))*math.Pow(x3, 2) + 2*math.Exp(math.Pow(x2, 2)+math.Pow(x3, 2))
+
+	return hess
+}
+
+// TaskExp - целевая функция f = x1^2 + 2*x2^2 + x1^2*x2^2 + 2*x3 + exp(x2^2 + x3^2) - x2.
+var TaskExp = NewHessianObjective(3, FExp, GradFExp, HessianExp)
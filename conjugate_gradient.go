@@ -1,110 +1,377 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/preconditioner"
+	"os"
+	"strings"
 )
 
+// betaFormula - формула коэффициента beta в направлении d_next = -grad_next + beta * d.
+// Обозначения: g = grad, g₊ = grad_next, y = g₊ - g.
+// Формулы записаны без предобусловливания; с предобусловливателем M в числителях
+// и знаменателях g₊ᵀg₊, gᵀg и g₊ᵀy заменяются на g₊ᵀz₊, gᵀz и z₊ᵀy, где z = M⁻¹g.
+type betaFormula int
+
+const (
+	// FletcherReeves: β = g₊ᵀg₊ / gᵀg. Направление спуска гарантировано при сильных
+	// условиях Вольфе с c2 < 1/2; без рестартов может "застревать" на коротких шагах.
+	FletcherReeves betaFormula = iota
+	// PolakRibiere (PR+): β = max(0, g₊ᵀy / gᵀg). При коротких шагах β ≈ 0 и метод
+	// сам возвращается к антиградиенту; спуск гарантирован при сильных условиях
+	// Вольфе вместе с условием достаточного спуска (Гильберт, Нокедал).
+	PolakRibiere
+	// HestenesStiefel: β = g₊ᵀy / dᵀy. При точном поиске совпадает с PR, всегда
+	// дает dᵀ_next y = 0 (сопряженность), но спуск в общем случае не гарантирован.
+	HestenesStiefel
+	// DaiYuan: β = g₊ᵀg₊ / dᵀy. Спуск гарантирован при обычных (не сильных)
+	// условиях Вольфе с любым c2 < 1.
+	DaiYuan
+	// ConjugateDescent (Флетчер): β = g₊ᵀg₊ / (-dᵀg). Спуск гарантирован при
+	// сильных условиях Вольфе с любым c2 < 1.
+	ConjugateDescent
+	// LiuStorey: β = g₊ᵀy / (-dᵀg). Ведет себя как PR, гарантий спуска нет.
+	LiuStorey
+	// HybridHSDY: β = max(0, min(β_HS, β_DY)). Спуск гарантирован при обычных условиях
+	// Вольфе (как DY), а на практике метод так же эффективен, как HS.
+	HybridHSDY
+	// HagerZhang (CG_DESCENT): β = (y - 2d·‖y‖²/dᵀy)ᵀg₊ / dᵀy, ограниченное снизу
+	// величиной -1/(‖d‖·min(0.01, ‖g‖)). Дает достаточный спуск
+	// g₊ᵀd_next ≤ -7/8·‖g₊‖² независимо от одномерного поиска.
+	HagerZhang
+)
+
+// betaNames - краткие имена формул для флагов командной строки.
+var betaNames = map[betaFormula]string{
+	FletcherReeves:   "FR",
+	PolakRibiere:     "PR",
+	HestenesStiefel:  "HS",
+	DaiYuan:          "DY",
+	ConjugateDescent: "CD",
+	LiuStorey:        "LS",
+	HybridHSDY:       "HS-DY",
+	HagerZhang:       "HZ",
+}
+
+func (b betaFormula) String() string {
+	if name, ok := betaNames[b]; ok {
+		return name
+	}
+	return fmt.Sprintf("betaFormula(%d)", int(b))
+}
+
+// title возвращает полное название метода для вывода результатов.
+func (b betaFormula) title() string {
+	switch b {
+	case FletcherReeves:
+		return "Флетчер-Ривз"
+	case PolakRibiere:
+		return "Полак-Рибьер"
+	case HestenesStiefel:
+		return "Хестенс-Штифель"
+	case DaiYuan:
+		return "Дай-Юань"
+	case ConjugateDescent:
+		return "сопряженный спуск"
+	case LiuStorey:
+		return "Лю-Стори"
+	case HybridHSDY:
+		return "гибрид HS-DY"
+	case HagerZhang:
+		return "Хагер-Чжан"
+	}
+	return b.String()
+}
+
+// betaByName возвращает формулу по краткому имени ("FR", "PR", "HS", ...).
+func betaByName(name string) (betaFormula, bool) {
+	for b, n := range betaNames {
+		if n == name {
+			return b, true
+		}
+	}
+	return 0, false
+}
+
+// computeBeta вычисляет beta по выбранной формуле. z и zNext - предобусловленные
+// градиенты M⁻¹g и M⁻¹g₊ (без предобусловливания совпадают с grad и gradNext).
+// При знаменателе, близком к нулю, возвращается 0 (рестарт по антиградиенту).
+func computeBeta(method betaFormula, grad, gradNext, z, zNext, direction []float64, precond preconditioner.Preconditioner) float64 {
+	y := common_funcs.VectorSub(gradNext, grad)
+	gg := common_funcs.DotProduct(grad, z)
+	gNextGNext := common_funcs.DotProduct(gradNext, zNext)
+	gNextY := common_funcs.DotProduct(zNext, y)
+	dy := common_funcs.DotProduct(direction, y)
+	dg := -common_funcs.DotProduct(direction, grad) // -dᵀg > 0 для направления спуска
+
+	safeDiv := func(num, den float64) float64 {
+		if math.Abs(den) <= 1e-12 { // Избегаем деления на ноль
+			return 0
+		}
+		return num / den
+	}
+
+	switch method {
+	case FletcherReeves: // Флетчер-Ривз
+		return safeDiv(gNextGNext, gg)
+	case PolakRibiere: // Полак-Рибьер
+		// beta = dot(grad_next, grad_next - grad) / dot(grad, grad)
+		// Часто используют max(0, beta) для Полака-Рибьера для улучшения сходимости
+		return math.Max(0, safeDiv(gNextY, gg))
+	case HestenesStiefel: // Хестенс-Штифель
+		return safeDiv(gNextY, dy)
+	case DaiYuan: // Дай-Юань
+		return safeDiv(gNextGNext, dy)
+	case ConjugateDescent: // Сопряженный спуск Флетчера
+		return safeDiv(gNextGNext, dg)
+	case LiuStorey: // Лю-Стори
+		return safeDiv(gNextY, dg)
+	case HybridHSDY: // Гибрид HS-DY
+		return math.Max(0, math.Min(safeDiv(gNextY, dy), safeDiv(gNextGNext, dy)))
+	case HagerZhang: // Хагер-Чжан
+		if math.Abs(dy) <= 1e-12 {
+			return 0
+		}
+		yy := common_funcs.DotProduct(y, precond.Apply(y)) // yᵀM⁻¹y
+		beta := (gNextY - 2*yy/dy*common_funcs.DotProduct(direction, gradNext)) / dy
+		eta := -1 / (common_funcs.VectorNorm(direction) * math.Min(0.01, math.Sqrt(gg)))
+		return math.Max(beta, eta)
+	}
+	panic(fmt.Sprintf("Неизвестный тип метода сопряженных градиентов: %s", method))
+}
+
+// restartOptions - правила рестарта метода сопряженных градиентов.
+type restartOptions struct {
+	interval    int  // Периодический рестарт каждые interval итераций (0 - не выполнять)
+	powell      bool // Рестарт Пауэлла при потере ортогональности: |g₊ᵀg| ≥ 0.2‖g₊‖²
+	nonDescent  bool // Рестарт, если новое направление не является направлением спуска
+	bealePowell bool // Рестарт трехчленными направлениями Била-Пауэлла вместо антиградиента
+}
+
+// powellRestartRatio - порог теста ортогональности Пауэлла (1977).
+const powellRestartRatio = 0.2
+
+// restartCounts - количество рестартов по каждой причине за один запуск.
+type restartCounts struct {
+	periodic   int // Периодические
+	powell     int // По тесту ортогональности Пауэлла
+	nonDescent int // Направление не давало (достаточного) спуска
+}
+
+func (c restartCounts) String() string {
+	return fmt.Sprintf("периодических: %d, по тесту Пауэлла: %d, из-за недостаточного спуска: %d", c.periodic, c.powell, c.nonDescent)
+}
+
 // conjugateGradient реализует Метод сопряженных градиентов.
+// obj - целевая функция.
 // startPoint - начальная точка.
 // epsilon - точность (норма градиента).
 // maxIter - максимальное количество итераций.
-// lineSearchMaxAlpha - верхняя граница для поиска шага alpha.
-// lineSearchTol - точность для метода золотого сечения.
-// methodType - тип метода ("FR" для Флетчера-Ривза, "PR" для Полака-Рибьера).
-// resetInterval - интервал для сброса направления d к антиградиенту (0 - не сбрасывать).
-// Возвращает найденную точку минимума и количество итераций.
-func conjugateGradient(startPoint []float64, epsilon float64, maxIter int, lineSearchMaxAlpha, lineSearchTol float64, methodType string, resetInterval int) ([]float64, int) {
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// Для Флетчера-Ривза направление спуска гарантировано только при сильных условиях
+// Вольфе с c2 < 1/2 (common_funcs.DefaultWolfeCG, флаг -linesearch wolfe-cg).
+// method - формула beta (FletcherReeves, PolakRibiere, HestenesStiefel, DaiYuan, ...).
+// restarts - правила рестарта. Обычный рестарт заменяет направление антиградиентом.
+// При restarts.bealePowell направление после рестарта строится по формуле Била
+// d_next = -g₊ + beta·d + gamma·d_t, gamma = g₊ᵀy_t / d_tᵀy_t, где (d_t, y_t) -
+// направление и изменение градиента на итерации рестарта, поэтому накопленная
+// информация о кривизне не теряется. Если трехчленное направление не дает
+// достаточного спуска (-1.2‖g₊‖² ≤ g₊ᵀd_next ≤ -0.8‖g₊‖²), оно заменяется антиградиентом.
+// precond - предобусловливатель M (preconditioner.Identity{} - без него). Вместо
+// антиградиента используется -z = -M⁻¹g: d_next = -z₊ + beta·d, а в формулах beta
+// и в тестах рестарта скалярные произведения берутся в метрике M⁻¹.
+// Возвращает найденную точку минимума, количество итераций и количество рестартов.
+func conjugateGradient(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, method betaFormula, restarts restartOptions, precond preconditioner.Preconditioner) ([]float64, int, restartCounts) {
 	x := make([]float64, len(startPoint))
 	copy(x, startPoint)
 	iter := 0
-	grad := common_funcs.GradF(x)                    // Начальный градиент
-	direction := common_funcs.ScalarMult(-1.0, grad) // Начальное направление d0 = -grad0
-
-	gradNormSq := common_funcs.DotProduct(grad, grad) // Квадрат нормы начального градиента
+	var counts restartCounts
+	grad := obj.Gradient(x) // Начальный градиент
+	precond.Update(x, grad)
+	z := precond.Apply(grad)                      // Предобусловленный градиент z0 = M⁻¹grad0
+	direction := common_funcs.ScalarMult(-1.0, z) // Начальное направление d0 = -z0
+	var restartDir, restartY []float64            // Пара (d_t, y_t) последнего рестарта Била-Пауэлла
 
 	// Основной цикл метода
 	for iter < maxIter {
-		gradNorm := math.Sqrt(gradNormSq) // Текущая норма градиента
+		gradNorm := common_funcs.VectorNorm(grad) // Текущая норма градиента
 
 		// Критерий остановки
 		if gradNorm < epsilon {
 			break
 		}
 
-		// Периодический сброс (рестарт) направления к антиградиенту
-		if resetInterval > 0 && iter%resetInterval == 0 && iter > 0 {
-			direction = common_funcs.ScalarMult(-1.0, grad)
-			// fmt.Println("Сброс направления CG на итерации", iter)
-		}
-
 		// 1. Ищем шаг alpha с помощью одномерного поиска
-		alpha := common_funcs.GoldenSectionSearch(x, direction, 0.0, lineSearchMaxAlpha, lineSearchTol)
+		alpha := lineSearch.Step(obj, x, direction, grad)
 
 		// 2. Обновляем точку: x_next = x + alpha * d
 		xNext := common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
 
 		// 3. Вычисляем новый градиент
-		gradNext := common_funcs.GradF(xNext)
-		gradNormSqNext := common_funcs.DotProduct(gradNext, gradNext) // Квадрат нормы нового градиента
+		gradNext := obj.Gradient(xNext)
+		precond.Update(xNext, gradNext)
+		zNext := precond.Apply(gradNext)
+		gradZNext := common_funcs.DotProduct(gradNext, zNext)
 
 		// 4. Вычисляем beta по выбранной формуле
-		beta := 0.0
-		if gradNormSq > 1e-12 { // Избегаем деления на ноль
-			switch methodType {
-			case "FR": // Флетчер-Ривз
-				beta = gradNormSqNext / gradNormSq
-			case "PR": // Полак-Рибьер
-				// beta = dot(grad_next, grad_next - grad) / dot(grad, grad)
-				beta = common_funcs.DotProduct(gradNext, common_funcs.VectorSub(gradNext, grad)) / gradNormSq
-				// Часто используют max(0, beta) для Полака-Рибьера для улучшения сходимости
-				if beta < 0 {
-					beta = 0
-				}
-			default:
-				panic("Неизвестный тип метода сопряженных градиентов: " + methodType)
+		beta := computeBeta(method, grad, gradNext, z, zNext, direction, precond)
+
+		// 5. Обновляем направление: d_next = -z_next + beta * d
+		antiGrad := common_funcs.ScalarMult(-1.0, zNext)
+		nextDirection := common_funcs.VectorAdd(antiGrad, common_funcs.ScalarMult(beta, direction))
+
+		// 6. Проверяем, нужен ли рестарт
+		restart := false
+		switch {
+		case restarts.interval > 0 && (iter+1)%restarts.interval == 0:
+			counts.periodic++
+			restart = true
+		case restarts.powell && math.Abs(common_funcs.DotProduct(gradNext, z)) >= powellRestartRatio*gradZNext:
+			counts.powell++
+			restart = true
+		}
+		switch {
+		case restart && restarts.bealePowell:
+			// Новая пара рестарта; направление остается двухчленным
+			restartDir, restartY = direction, common_funcs.VectorSub(gradNext, grad)
+		case restart:
+			nextDirection = antiGrad
+			restartDir, restartY = nil, nil
+		case restartDir != nil:
+			// Трехчленное направление Била
+			dy := common_funcs.DotProduct(restartDir, restartY)
+			if dy != 0 {
+				gamma := common_funcs.DotProduct(zNext, restartY) / dy
+				nextDirection = common_funcs.VectorAdd(nextDirection, common_funcs.ScalarMult(gamma, restartDir))
+			}
+			slope := common_funcs.DotProduct(gradNext, nextDirection)
+			if slope > -0.8*gradZNext || slope < -1.2*gradZNext {
+				counts.nonDescent++
+				nextDirection = antiGrad
+				restartDir, restartY = nil, nil
 			}
 		}
-
-		// 5. Обновляем направление: d_next = -grad_next + beta * d
-		direction = common_funcs.VectorAdd(common_funcs.ScalarMult(-1.0, gradNext), common_funcs.ScalarMult(beta, direction))
+		if restarts.nonDescent && common_funcs.DotProduct(gradNext, nextDirection) >= 0 {
+			counts.nonDescent++
+			nextDirection = antiGrad
+			restartDir, restartY = nil, nil
+		}
+		direction = nextDirection
 
 		// Переходим к следующей итерации
 		x = xNext
 		grad = gradNext
-		gradNormSq = gradNormSqNext // Обновляем квадрат нормы
+		z = zNext
 		iter++
 	}
 
 	// Сообщение, если достигнуто максимальное количество итераций
 	if iter == maxIter {
-		fmt.Printf("Метод сопряженных градиентов (%s) достиг максимального числа итераций.\n", methodType)
+		fmt.Printf("Метод сопряженных градиентов (%s) достиг максимального числа итераций.\n", method)
 	}
-	return x, iter // Возвращаем результат
+	return x, iter, counts // Возвращаем результат
+}
+
+// preconditionerNames - допустимые значения флага -precond.
+var preconditionerNames = []string{"none", "diag", "ichol", "lbfgs"}
+
+// newPreconditioner создает предобусловливатель по имени. Предобусловливатели хранят
+// состояние, поэтому для каждого запуска метода создается новый.
+func newPreconditioner(name string, obj common_funcs.HessianObjective) (preconditioner.Preconditioner, bool) {
+	switch name {
+	case "none":
+		return preconditioner.Identity{}, true
+	case "diag":
+		return preconditioner.NewDiagonal(obj, 1), true
+	case "ichol":
+		return preconditioner.NewIncompleteCholesky(obj, 1), true
+	case "lbfgs":
+		return preconditioner.NewLBFGS(5), true
+	}
+	return nil, false
 }
 
 func main() {
-	startPoint := []float64{0.0, 0.0, 0.0} // Начальная точка 3D
-	epsilon := 1e-5                        // Точность
-	maxIter := 1000                        // Макс. итераций
-	lineSearchMaxAlpha := 1.0              // Макс. alpha для GSS
-	lineSearchTol := 1e-6                  // Точность для GSS
-	resetInterval := 5 * len(startPoint)   // Интервал сброса (например, каждые 5*n итераций)
-
-	// --- Запуск Флетчера-Ривза ---
-	minX_FR, iterations_FR := conjugateGradient(startPoint, epsilon, maxIter, lineSearchMaxAlpha, lineSearchTol, "FR", resetInterval)
-	minF_FR := common_funcs.F(minX_FR)
-	fmt.Println("\nМетод сопряженных градиентов (Флетчер-Ривз):")
-	fmt.Printf("Найденный минимум x: [%.6f, %.6f, %.6f]\n", minX_FR[0], minX_FR[1], minX_FR[2])
-	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF_FR)
-	fmt.Printf("Количество итераций: %d\n", iterations_FR)
-
-	// --- Запуск Полака-Рибьера ---
-	minX_PR, iterations_PR := conjugateGradient(startPoint, epsilon, maxIter, lineSearchMaxAlpha, lineSearchTol, "PR", resetInterval)
-	minF_PR := common_funcs.F(minX_PR)
-	fmt.Println("\nМетод сопряженных градиентов (Полак-Рибьер):")
-	fmt.Printf("Найденный минимум x: [%.6f, %.6f, %.6f]\n", minX_PR[0], minX_PR[1], minX_PR[2])
-	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF_PR)
-	fmt.Printf("Количество итераций: %d\n", iterations_PR)
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden,armijo", "одномерный поиск через запятую: "+common_funcs.LineSearchUsage)
+	restartPowell := flag.Bool("powell", true, "рестарт по тесту ортогональности Пауэлла")
+	restartDescent := flag.Bool("descent", true, "рестарт, если направление не является направлением спуска")
+	restartBeale := flag.Bool("beale", false, "трехчленные рестарты Била-Пауэлла вместо антиградиента")
+	betaList := flag.String("beta", "FR,PR,HS,DY,CD,LS,HS-DY,HZ", "формулы beta через запятую")
+	precondList := flag.String("precond", "none", "предобусловливатели через запятую: "+strings.Join(preconditionerNames, ", "))
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	var methods []betaFormula
+	for _, name := range strings.Split(*betaList, ",") {
+		method, ok := betaByName(strings.TrimSpace(name))
+		if !ok {
+			fmt.Println("Неизвестная формула beta:", name)
+			os.Exit(2)
+		}
+		methods = append(methods, method)
+	}
+	var precondNames []string
+	for _, name := range strings.Split(*precondList, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := newPreconditioner(name, obj); !ok {
+			fmt.Println("Неизвестный предобусловливатель:", name)
+			os.Exit(2)
+		}
+		precondNames = append(precondNames, name)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 1000                                // Макс. итераций
+	restarts := restartOptions{
+		interval:    5 * len(startPoint), // Интервал сброса (например, каждые 5*n итераций)
+		powell:      *restartPowell,
+		nonDescent:  *restartDescent,
+		bealePowell: *restartBeale,
+	}
+
+	baseline := common_funcs.CostBaseline{}
+	for _, lineSearch := range lineSearches {
+		for _, method := range methods {
+			for _, precondName := range precondNames {
+				// Вызываем метод, считая вычисления функции и производных
+				// (включая Гессианы, нужные предобусловливателю)
+				counted := common_funcs.NewCountingObjective(obj)
+				precond, _ := newPreconditioner(precondName, counted)
+				minX, iterations, restartCount := conjugateGradient(counted, startPoint, epsilon, maxIter, lineSearch, method, restarts, precond)
+				minF := obj.Value(minX) // Значение функции в минимуме
+
+				// Выводим результаты
+				fmt.Printf("\nМетод сопряженных градиентов (%s, %s, %s):\n", method.title(), lineSearch, precond)
+				fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+				fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+				fmt.Printf("Количество итераций: %d\n", iterations)
+				fmt.Println("Рестарты:", restartCount)
+				fmt.Println("Стоимость:", counted)
+				if saving := baseline.Compare(method.String()+"/"+precondName, lineSearch, counted); saving != "" {
+					fmt.Println("По сравнению с золотым сечением:", saving)
+				}
+			}
+		}
+	}
 }
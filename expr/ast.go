@@ -0,0 +1,84 @@
+// Package expr разбирает целевую функцию, заданную строкой, например
+// "2*x1^4 + x2^4 + x1^2*x2^2 + x3^4 + x1^2*x3^2 + x1 + x2",
+// и компилирует её в вычислитель, реализующий целевую функцию для всех методов.
+//
+// Поддерживаются операции + - * / ^ (и ** как синоним ^), унарный минус,
+// скобки, функции exp, log (ln), sin, cos, sqrt, константы pi и e и
+// именованные переменные.
+package expr
+
+import "strings"
+
+// Node - узел дерева выражения.
+type Node interface {
+	node()
+}
+
+// Num - числовая константа.
+type Num struct {
+	Value float64
+}
+
+// Var - переменная. Index - номер координаты во входном векторе.
+type Var struct {
+	Name  string
+	Index int
+}
+
+// Unary - унарная операция (только '-').
+type Unary struct {
+	Op byte
+	X  Node
+}
+
+// Binary - бинарная операция: '+', '-', '*', '/', '^'.
+type Binary struct {
+	Op   byte
+	L, R Node
+}
+
+// Call - вызов элементарной функции одного аргумента.
+type Call struct {
+	Func string
+	Arg  Node
+}
+
+func (Num) node()    {}
+func (Var) node()    {}
+func (Unary) node()  {}
+func (Binary) node() {}
+func (Call) node()   {}
+
+// Expr - разобранное выражение вместе со списком переменных.
+// Vars[i] - имя переменной, соответствующей координате x[i].
+type Expr struct {
+	Root Node
+	Vars []string
+}
+
+// Dimension возвращает размерность пространства переменных выражения.
+func (e *Expr) Dimension() int {
+	return len(e.Vars)
+}
+
+// functions - поддерживаемые элементарные функции (ln - синоним log).
+var functions = map[string]string{
+	"exp":  "exp",
+	"log":  "log",
+	"ln":   "log",
+	"sin":  "sin",
+	"cos":  "cos",
+	"sqrt": "sqrt",
+}
+
+// constants - именованные константы.
+var constants = map[string]float64{
+	"pi": 3.141592653589793,
+	"e":  2.718281828459045,
+}
+
+// isFunction сообщает, является ли имя именем функции.
+func isFunction(name string) bool {
+	_, ok := functions[strings.ToLower(name)]
+	return ok
+}
@@ -0,0 +1,232 @@
+package expr
+
+import (
+	"math"
+
+	"optimizationMethodsTask4/autodiff"
+)
+
+// Compile превращает дерево выражения в функцию вещественного аргумента.
+// Дерево обходится один раз: результат - цепочка замыканий без повторного
+// разбора, а целые степени раскрываются в умножения вместо math.Pow.
+func (e *Expr) Compile() func([]float64) float64 {
+	return compileFloat(e.Root)
+}
+
+func compileFloat(n Node) func([]float64) float64 {
+	if c, ok := constValue(n); ok {
+		return func([]float64) float64 { return c }
+	}
+	switch n := n.(type) {
+	case Var:
+		i := n.Index
+		return func(x []float64) float64 { return x[i] }
+	case Unary:
+		a := compileFloat(n.X)
+		return func(x []float64) float64 { return -a(x) }
+	case Binary:
+		if n.Op == '^' {
+			if p, ok := constValue(n.R); ok {
+				return compileFloatPow(compileFloat(n.L), p)
+			}
+		}
+		a, b := compileFloat(n.L), compileFloat(n.R)
+		switch n.Op {
+		case '+':
+			return func(x []float64) float64 { return a(x) + b(x) }
+		case '-':
+			return func(x []float64) float64 { return a(x) - b(x) }
+		case '*':
+			return func(x []float64) float64 { return a(x) * b(x) }
+		case '/':
+			return func(x []float64) float64 { return a(x) / b(x) }
+		default:
+			return func(x []float64) float64 { return math.Pow(a(x), b(x)) }
+		}
+	case Call:
+		a := compileFloat(n.Arg)
+		f := floatFunc(n.Func)
+		return func(x []float64) float64 { return f(a(x)) }
+	default:
+		panic("Неизвестный узел выражения")
+	}
+}
+
+// compileFloatPow раскрывает малые целые степени в умножения.
+func compileFloatPow(a func([]float64) float64, p float64) func([]float64) float64 {
+	switch p {
+	case 1:
+		return a
+	case 2:
+		return func(x []float64) float64 { v := a(x); return v * v }
+	case 3:
+		return func(x []float64) float64 { v := a(x); return v * v * v }
+	case 4:
+		return func(x []float64) float64 { v := a(x); v *= v; return v * v }
+	case 0.5:
+		return func(x []float64) float64 { return math.Sqrt(a(x)) }
+	}
+	return func(x []float64) float64 { return math.Pow(a(x), p) }
+}
+
+// floatFunc возвращает вещественную реализацию элементарной функции.
+func floatFunc(name string) func(float64) float64 {
+	switch name {
+	case "exp":
+		return math.Exp
+	case "log":
+		return math.Log
+	case "sin":
+		return math.Sin
+	case "cos":
+		return math.Cos
+	case "sqrt":
+		return math.Sqrt
+	}
+	panic("Неизвестная функция: " + name)
+}
+
+// constValue вычисляет поддерево, если оно не зависит от переменных.
+func constValue(n Node) (float64, bool) {
+	switch n := n.(type) {
+	case Num:
+		return n.Value, true
+	case Var:
+		return 0, false
+	case Unary:
+		v, ok := constValue(n.X)
+		return -v, ok
+	case Binary:
+		a, okA := constValue(n.L)
+		b, okB := constValue(n.R)
+		if !okA || !okB {
+			return 0, false
+		}
+		switch n.Op {
+		case '+':
+			return a + b, true
+		case '-':
+			return a - b, true
+		case '*':
+			return a * b, true
+		case '/':
+			return a / b, true
+		default:
+			return math.Pow(a, b), true
+		}
+	case Call:
+		v, ok := constValue(n.Arg)
+		if !ok {
+			return 0, false
+		}
+		return floatFunc(n.Func)(v), true
+	}
+	return 0, false
+}
+
+// CompileNumber превращает выражение в обобщенную функцию для типов
+// автоматического дифференцирования (autodiff.Dual, autodiff.HyperDual, autodiff.Var).
+// Константные поддеревья сворачиваются, а операции с константой выражаются
+// через Scale, AddConst и Pow, поэтому узлы-константы на ленту не попадают.
+func CompileNumber[T autodiff.Number[T]](e *Expr) func([]T) T {
+	c := compileNumber[T](e.Root)
+	return func(x []T) T {
+		if c.isConst {
+			// Выражение без переменных: строим константу из первой координаты
+			return x[0].Scale(0).AddConst(c.value)
+		}
+		return c.f(x)
+	}
+}
+
+// numberCode - скомпилированное поддерево: либо константа, либо функция от T.
+type numberCode[T autodiff.Number[T]] struct {
+	isConst bool
+	value   float64
+	f       func([]T) T
+}
+
+func compileNumber[T autodiff.Number[T]](n Node) numberCode[T] {
+	if c, ok := constValue(n); ok {
+		return numberCode[T]{isConst: true, value: c}
+	}
+	switch n := n.(type) {
+	case Var:
+		i := n.Index
+		return numberCode[T]{f: func(x []T) T { return x[i] }}
+	case Unary:
+		a := compileNumber[T](n.X).f
+		return numberCode[T]{f: func(x []T) T { return a(x).Neg() }}
+	case Binary:
+		return numberCode[T]{f: compileNumberBinary(n.Op, compileNumber[T](n.L), compileNumber[T](n.R))}
+	case Call:
+		a := compileNumber[T](n.Arg).f
+		var f func(T) T
+		switch n.Func {
+		case "exp":
+			f = T.Exp
+		case "log":
+			f = T.Log
+		case "sin":
+			f = T.Sin
+		case "cos":
+			f = T.Cos
+		case "sqrt":
+			f = T.Sqrt
+		default:
+			panic("Неизвестная функция: " + n.Func)
+		}
+		return numberCode[T]{f: func(x []T) T { return f(a(x)) }}
+	default:
+		panic("Неизвестный узел выражения")
+	}
+}
+
+// compileNumberBinary компилирует бинарную операцию, в которой хотя бы один
+// операнд зависит от переменных.
+func compileNumberBinary[T autodiff.Number[T]](op byte, l, r numberCode[T]) func([]T) T {
+	a, b := l.f, r.f
+	switch {
+	case l.isConst:
+		c := l.value
+		switch op {
+		case '+':
+			return func(x []T) T { return b(x).AddConst(c) }
+		case '-':
+			return func(x []T) T { return b(x).Neg().AddConst(c) }
+		case '*':
+			return func(x []T) T { return b(x).Scale(c) }
+		case '/':
+			return func(x []T) T { return b(x).Pow(-1).Scale(c) }
+		default: // c^b = e^(b·ln c)
+			lnc := math.Log(c)
+			return func(x []T) T { return b(x).Scale(lnc).Exp() }
+		}
+	case r.isConst:
+		c := r.value
+		switch op {
+		case '+':
+			return func(x []T) T { return a(x).AddConst(c) }
+		case '-':
+			return func(x []T) T { return a(x).AddConst(-c) }
+		case '*':
+			return func(x []T) T { return a(x).Scale(c) }
+		case '/':
+			return func(x []T) T { return a(x).Scale(1 / c) }
+		default:
+			return func(x []T) T { return a(x).Pow(c) }
+		}
+	}
+	switch op {
+	case '+':
+		return func(x []T) T { return a(x).Add(b(x)) }
+	case '-':
+		return func(x []T) T { return a(x).Sub(b(x)) }
+	case '*':
+		return func(x []T) T { return a(x).Mul(b(x)) }
+	case '/':
+		return func(x []T) T { return a(x).Div(b(x)) }
+	default: // a^b = e^(b·ln a)
+		return func(x []T) T { return b(x).Mul(a(x).Log()).Exp() }
+	}
+}
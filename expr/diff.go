@@ -0,0 +1,99 @@
+package expr
+
+import "math"
+
+// Diff возвращает символьную производную выражения n по переменной с индексом i.
+// Результат не упрощен; используйте Simplify или Expr.Derivative.
+func Diff(n Node, i int) Node {
+	switch n := n.(type) {
+	case Num:
+		return Num{Value: 0}
+	case Var:
+		if n.Index == i {
+			return Num{Value: 1}
+		}
+		return Num{Value: 0}
+	case Unary:
+		return Unary{Op: '-', X: Diff(n.X, i)}
+	case Binary:
+		u, v := n.L, n.R
+		du, dv := Diff(u, i), Diff(v, i)
+		switch n.Op {
+		case '+', '-':
+			return Binary{Op: n.Op, L: du, R: dv}
+		case '*': // (uv)' = u'v + uv'
+			return add(mul(du, v), mul(u, dv))
+		case '/': // (u/v)' = (u'v - uv') / v²
+			return div(sub(mul(du, v), mul(u, dv)), pow(v, Num{Value: 2}))
+		default:
+			if c, ok := constValue(v); ok { // (u^c)' = c·u^(c-1)·u'
+				return mul(mul(Num{Value: c}, pow(u, Num{Value: c - 1})), du)
+			}
+			if c, ok := constValue(u); ok { // (c^v)' = c^v·ln(c)·v'
+				return mul(mul(n, Num{Value: math.Log(c)}), dv)
+			}
+			// (u^v)' = u^v·(v'·ln(u) + v·u'/u)
+			return mul(n, add(mul(dv, Call{Func: "log", Arg: u}), div(mul(v, du), u)))
+		}
+	case Call:
+		u := n.Arg
+		du := Diff(u, i)
+		switch n.Func {
+		case "exp":
+			return mul(n, du)
+		case "log":
+			return div(du, u)
+		case "sin":
+			return mul(Call{Func: "cos", Arg: u}, du)
+		case "cos":
+			return Unary{Op: '-', X: mul(Call{Func: "sin", Arg: u}, du)}
+		case "sqrt":
+			return div(du, mul(Num{Value: 2}, n))
+		}
+		panic("Неизвестная функция: " + n.Func)
+	}
+	panic("Неизвестный узел выражения")
+}
+
+func add(a, b Node) Node { return Binary{Op: '+', L: a, R: b} }
+func sub(a, b Node) Node { return Binary{Op: '-', L: a, R: b} }
+func mul(a, b Node) Node { return Binary{Op: '*', L: a, R: b} }
+func div(a, b Node) Node { return Binary{Op: '/', L: a, R: b} }
+func pow(a, b Node) Node { return Binary{Op: '^', L: a, R: b} }
+
+// Derivative возвращает упрощенную производную выражения по переменной x[i].
+func (e *Expr) Derivative(i int) *Expr {
+	return &Expr{Root: Simplify(Diff(e.Root, i)), Vars: e.Vars}
+}
+
+// Gradient возвращает символьный градиент выражения.
+func (e *Expr) Gradient() []*Expr {
+	grad := make([]*Expr, e.Dimension())
+	for i := range grad {
+		grad[i] = e.Derivative(i)
+	}
+	return grad
+}
+
+// Hessian возвращает символьную матрицу Гессе выражения.
+// Элементы ниже диагонали совпадают (как указатели) с симметричными им.
+func (e *Expr) Hessian() [][]*Expr {
+	n := e.Dimension()
+	grad := e.Gradient()
+	hess := make([][]*Expr, n)
+	for i := range hess {
+		hess[i] = make([]*Expr, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			hess[i][j] = grad[i].Derivative(j)
+			hess[j][i] = hess[i][j] // Симметричная
+		}
+	}
+	return hess
+}
+
+// Simplify возвращает упрощенную копию выражения.
+func (e *Expr) Simplify() *Expr {
+	return &Expr{Root: Simplify(e.Root), Vars: e.Vars}
+}
@@ -0,0 +1,98 @@
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"go/types"
+)
+
+// commonFuncsStub - объявления common_funcs, которые использует сгенерированный код.
+// При генерации в сам пакет common_funcs они добавляются к проверяемому файлу.
+const commonFuncsStub = `package common_funcs
+
+type Matrix [][]float64
+
+func NewMatrix(n, m int) Matrix { return nil }
+
+func NewHessianObjective(dim int, f func([]float64) float64, grad func([]float64) []float64, hess func([]float64) Matrix) any {
+	return nil
+}
+`
+
+// checkGo проверяет типы сгенерированного файла: format.Source проверяет только
+// синтаксис, а конфликт имени переменной с используемым идентификатором
+// (например, make или Matrix) обнаружился бы лишь при сборке. Импортируемые
+// пакеты заменяются заглушками с объявлениями, нужными генератору.
+func checkGo(src []byte, inCommon bool) error {
+	fset := gotoken.NewFileSet()
+	file, err := goparser.ParseFile(fset, "generated.go", src, 0)
+	if err != nil {
+		return err
+	}
+	files := []*ast.File{file}
+	if inCommon {
+		stub, err := goparser.ParseFile(fset, "stub.go", commonFuncsStub, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, stub)
+	}
+	conf := types.Config{Importer: stubImporter{}}
+	_, err = conf.Check(file.Name.Name, fset, files, nil)
+	return err
+}
+
+// stubImporter возвращает заглушки пакетов fmt, math и common_funcs.
+type stubImporter struct{}
+
+func (stubImporter) Import(path string) (*types.Package, error) {
+	float := types.Typ[types.Float64]
+	floats := types.NewSlice(float)
+	param := func(pkg *types.Package, name string, t types.Type) *types.Var {
+		return types.NewParam(gotoken.NoPos, pkg, name, t)
+	}
+	addFunc := func(pkg *types.Package, name string, params, results []*types.Var, variadic bool) {
+		sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), variadic)
+		pkg.Scope().Insert(types.NewFunc(gotoken.NoPos, pkg, name, sig))
+	}
+
+	switch path {
+	case "fmt":
+		pkg := types.NewPackage("fmt", "fmt")
+		anys := types.NewSlice(types.Universe.Lookup("any").Type())
+		addFunc(pkg, "Sprintf", []*types.Var{param(pkg, "format", types.Typ[types.String]), param(pkg, "a", anys)},
+			[]*types.Var{param(pkg, "", types.Typ[types.String])}, true)
+		pkg.MarkComplete()
+		return pkg, nil
+	case "math":
+		pkg := types.NewPackage("math", "math")
+		result := []*types.Var{param(pkg, "", float)}
+		addFunc(pkg, "Pow", []*types.Var{param(pkg, "x", float), param(pkg, "y", float)}, result, false)
+		for _, name := range goMathFuncs {
+			addFunc(pkg, name, []*types.Var{param(pkg, "x", float)}, result, false)
+		}
+		pkg.MarkComplete()
+		return pkg, nil
+	case "optimizationMethodsTask4/common_funcs":
+		pkg := types.NewPackage(path, "common_funcs")
+		matrix := types.NewNamed(types.NewTypeName(gotoken.NoPos, pkg, "Matrix", nil), types.NewSlice(floats), nil)
+		pkg.Scope().Insert(matrix.Obj())
+		intType := types.Typ[types.Int]
+		addFunc(pkg, "NewMatrix", []*types.Var{param(pkg, "n", intType), param(pkg, "m", intType)},
+			[]*types.Var{param(pkg, "", matrix)}, false)
+		fn := func(params []*types.Var, result types.Type) types.Type {
+			return types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(param(pkg, "", result)), false)
+		}
+		addFunc(pkg, "NewHessianObjective", []*types.Var{
+			param(pkg, "dim", intType),
+			param(pkg, "f", fn([]*types.Var{param(pkg, "", floats)}, float)),
+			param(pkg, "grad", fn([]*types.Var{param(pkg, "", floats)}, floats)),
+			param(pkg, "hess", fn([]*types.Var{param(pkg, "", floats)}, matrix)),
+		}, []*types.Var{param(pkg, "", types.Universe.Lookup("any").Type())}, false)
+		pkg.MarkComplete()
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("неизвестный пакет %q", path)
+}
@@ -0,0 +1,276 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	gotoken "go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// GoOptions - параметры генерации Go-кода целевой функции.
+type GoOptions struct {
+	Package   string // Имя пакета сгенерированного файла
+	Suffix    string // Суффикс имен функций: F<Suffix>, GradF<Suffix>, Hessian<Suffix>
+	Objective string // Имя переменной с целевой функцией (пусто - не генерировать)
+	Formula   string // Исходная формула для комментария
+	Command   string // Команда, которой сгенерирован файл (для заголовка)
+}
+
+// GenerateGo генерирует исходный файл с функциями F, GradF и Hessian для выражения e
+// в том же виде, в каком они написаны вручную в common_funcs: проверка размерности,
+// распаковка координат, разбиение F на слагаемые term1, term2, ... и поэлементный Гессиан.
+func GenerateGo(e *Expr, opts GoOptions) ([]byte, error) {
+	n := e.Dimension()
+	inCommon := opts.Package == "common_funcs"
+	matrix, newMatrix, newObjective := "common_funcs.Matrix", "common_funcs.NewMatrix", "common_funcs.NewHessianObjective"
+	if inCommon {
+		matrix, newMatrix, newObjective = "Matrix", "NewMatrix", "NewHessianObjective"
+	}
+	fName, gradName, hessName := "F"+opts.Suffix, "GradF"+opts.Suffix, "Hessian"+opts.Suffix
+	names := goVarNames(e.Vars, "Matrix", "NewMatrix", "NewHessianObjective", fName, gradName, hessName, opts.Objective)
+
+	var b bytes.Buffer
+	simplified := Simplify(e.Root)
+	formula := opts.Formula
+	if formula == "" {
+		formula = String(simplified)
+	}
+
+	// --- F ---
+	var present []string
+	for _, v := range e.Vars {
+		if v != "" {
+			present = append(present, v)
+		}
+	}
+	fmt.Fprintf(&b, "// %s вычисляет значение целевой функции.\n// f(%s) = %s\n", fName, strings.Join(present, ", "), formula)
+	fmt.Fprintf(&b, "func %s(x []float64) float64 {\n", fName)
+	writeDimCheck(&b, fName, n)
+	terms := splitTerms(simplified)
+	writeUnpack(&b, e.Vars, names, terms...)
+	b.WriteString("\n\t// Разбиваем формулу на отдельные слагаемые для читаемости и отладки\n")
+	termNames := make([]string, len(terms))
+	for k, t := range terms {
+		termNames[k] = fmt.Sprintf("term%d", k+1)
+		fmt.Fprintf(&b, "\t%s := %s // %s\n", termNames[k], goExpr(t, names), String(t))
+	}
+	b.WriteString("\n\t// Возвращаем сумму всех слагаемых\n")
+	fmt.Fprintf(&b, "\treturn %s\n}\n\n", strings.Join(termNames, " + "))
+
+	// --- GradF ---
+	grad := e.Gradient()
+	gradNodes := make([]Node, n)
+	for i := range grad {
+		gradNodes[i] = grad[i].Root
+	}
+	fmt.Fprintf(&b, "// %s вычисляет градиент целевой функции в точке x.\n", gradName)
+	fmt.Fprintf(&b, "func %s(x []float64) []float64 {\n", gradName)
+	writeDimCheck(&b, gradName, n)
+	writeUnpack(&b, e.Vars, names, gradNodes...)
+	fmt.Fprintf(&b, "\tgrad := make([]float64, %d)\n", n)
+	for i, g := range gradNodes {
+		v := e.Vars[i]
+		if v == "" {
+			v = fmt.Sprintf("x[%d]", i) // Переменная не входит в формулу
+		}
+		fmt.Fprintf(&b, "\tgrad[%d] = %s // ∂f/∂%s\n", i, goExpr(g, names), v)
+	}
+	b.WriteString("\treturn grad\n}\n\n")
+
+	// --- Hessian ---
+	hess := e.Hessian()
+	var hessNodes []Node
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			hessNodes = append(hessNodes, hess[i][j].Root)
+		}
+	}
+	fmt.Fprintf(&b, "// %s вычисляет матрицу Гессе целевой функции в точке x.\n", hessName)
+	fmt.Fprintf(&b, "func %s(x []float64) %s {\n", hessName, matrix)
+	writeDimCheck(&b, hessName, n)
+	writeUnpack(&b, e.Vars, names, hessNodes...)
+	fmt.Fprintf(&b, "\thess := %s(%d, %d)\n\n", newMatrix, n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j < i {
+				fmt.Fprintf(&b, "\thess[%d][%d] = hess[%d][%d] // Симметричная\n", i, j, j, i)
+				continue
+			}
+			fmt.Fprintf(&b, "\thess[%d][%d] = %s\n", i, j, goExpr(hess[i][j].Root, names))
+		}
+	}
+	b.WriteString("\n\treturn hess\n}\n")
+
+	if opts.Objective != "" {
+		fmt.Fprintf(&b, "\n// %s - целевая функция f = %s.\n", opts.Objective, formula)
+		fmt.Fprintf(&b, "var %s = %s(%d, %s, %s, %s)\n", opts.Objective, newObjective, n, fName, gradName, hessName)
+	}
+
+	// Заголовок пишется в конце: пакет math нужен не во всякой формуле
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "// Code generated by %s; DO NOT EDIT.\n\n", opts.Command)
+	fmt.Fprintf(&head, "package %s\n\n", opts.Package)
+	head.WriteString("import (\n\t\"fmt\"\n")
+	if bytes.Contains(b.Bytes(), []byte("math.")) {
+		head.WriteString("\t\"math\"\n")
+	}
+	if !inCommon {
+		head.WriteString("\t\"optimizationMethodsTask4/common_funcs\"\n")
+	}
+	head.WriteString(")\n\n")
+	head.Write(b.Bytes())
+
+	src, err := format.Source(head.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("сгенерирован некорректный код: %v", err)
+	}
+	if err := checkGo(src, inCommon); err != nil {
+		return nil, fmt.Errorf("сгенерирован некорректный код: %v", err)
+	}
+	return src, nil
+}
+
+// writeDimCheck печатает проверку размерности входного вектора.
+func writeDimCheck(b *bytes.Buffer, name string, n int) {
+	fmt.Fprintf(b, "\tif len(x) != %d {\n", n)
+	fmt.Fprintf(b, "\t\tpanic(fmt.Sprintf(\"Функция %s ожидает %d-мерный вектор, получено: %%d\", len(x)))\n\t}\n", name, n)
+}
+
+// writeUnpack распаковывает координаты в именованные переменные, но только те,
+// что встречаются в nodes: иначе сгенерированный код не скомпилируется.
+func writeUnpack(b *bytes.Buffer, vars, names []string, nodes ...Node) {
+	used := map[int]bool{}
+	for _, n := range nodes {
+		collectVars(n, used)
+	}
+	for i := range vars {
+		if used[i] {
+			fmt.Fprintf(b, "\t%s := x[%d]\n", names[i], i)
+		}
+	}
+}
+
+func collectVars(n Node, used map[int]bool) {
+	switch n := n.(type) {
+	case Var:
+		used[n.Index] = true
+	case Unary:
+		collectVars(n.X, used)
+	case Binary:
+		collectVars(n.L, used)
+		collectVars(n.R, used)
+	case Call:
+		collectVars(n.Arg, used)
+	}
+}
+
+// splitTerms разбивает сумму верхнего уровня на слагаемые со знаками.
+func splitTerms(n Node) []Node {
+	if b, ok := n.(Binary); ok && (b.Op == '+' || b.Op == '-') {
+		terms := splitTerms(b.L)
+		if b.Op == '-' {
+			return append(terms, negate(b.R))
+		}
+		return append(terms, b.R)
+	}
+	return []Node{n}
+}
+
+// goVarNames подбирает для переменных допустимые имена Go, не совпадающие
+// с ключевыми словами, предопределенными идентификаторами (make, len, float64, ...),
+// локальными именами сгенерированных функций (x, grad, hess, term1, term2, ...)
+// и именами extra, которые использует сгенерированный код. Конфликтующее имя
+// дополняется символами "_", пока не перестанет совпадать с зарезервированным
+// или уже занятым именем.
+func goVarNames(vars []string, extra ...string) []string {
+	reserved := map[string]bool{"x": true, "grad": true, "hess": true, "math": true, "fmt": true, "common_funcs": true}
+	for _, name := range types.Universe.Names() {
+		reserved[name] = true
+	}
+	for _, name := range extra {
+		reserved[name] = true
+	}
+	conflicts := func(name string) bool {
+		return gotoken.IsKeyword(name) || reserved[name] || termName.MatchString(name)
+	}
+	taken := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		taken[v] = true
+	}
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		name := v
+		if conflicts(name) {
+			for conflicts(name) || taken[name] {
+				name += "_"
+			}
+			taken[name] = true
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// termName совпадает с именами локальных переменных-слагаемых term1, term2, ...
+var termName = regexp.MustCompile(`^term[0-9]+$`)
+
+// goMathFuncs - функции пакета math, которыми печатаются вызовы функций формулы.
+var goMathFuncs = map[string]string{"exp": "Exp", "log": "Log", "sin": "Sin", "cos": "Cos", "sqrt": "Sqrt"}
+
+// goExpr печатает выражение на Go: степени через math.Pow, функции из пакета math.
+func goExpr(n Node, names []string) string {
+	var sb strings.Builder
+	writeGo(&sb, n, names)
+	return sb.String()
+}
+
+// goPrec - приоритет узла в Go (степень становится вызовом math.Pow).
+func goPrec(n Node) int {
+	if b, ok := n.(Binary); ok && b.Op == '^' {
+		return precAtom
+	}
+	return prec(n)
+}
+
+func writeGoParen(sb *strings.Builder, n Node, names []string, paren bool) {
+	if paren {
+		sb.WriteByte('(')
+		writeGo(sb, n, names)
+		sb.WriteByte(')')
+		return
+	}
+	writeGo(sb, n, names)
+}
+
+func writeGo(sb *strings.Builder, n Node, names []string) {
+	switch n := n.(type) {
+	case Num:
+		sb.WriteString(formatNum(n.Value))
+	case Var:
+		sb.WriteString(names[n.Index])
+	case Unary:
+		sb.WriteByte('-')
+		writeGoParen(sb, n.X, names, goPrec(n.X) < precProd || goPrec(n.X) == precUnary)
+	case Binary:
+		if n.Op == '^' {
+			sb.WriteString("math.Pow(")
+			writeGo(sb, n.L, names)
+			sb.WriteString(", ")
+			writeGo(sb, n.R, names)
+			sb.WriteByte(')')
+			return
+		}
+		p := goPrec(n)
+		writeGoParen(sb, n.L, names, goPrec(n.L) < p)
+		sb.WriteString(" " + string(n.Op) + " ")
+		writeGoParen(sb, n.R, names, goPrec(n.R) <= p && (n.Op != '+' || goPrec(n.R) < p))
+	case Call:
+		name := goMathFuncs[n.Func]
+		sb.WriteString("math." + name + "(")
+		writeGo(sb, n.Arg, names)
+		sb.WriteByte(')')
+	}
+}
@@ -0,0 +1,163 @@
+package expr
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"optimizationMethodsTask4/autodiff"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// objective - целевая функция, заданная формулой.
+// Значение вычисляется скомпилированными замыканиями, градиент и произведение
+// Гессиана на вектор - лентой обратного режима, Гессиан - гипердуальными числами.
+type objective struct {
+	expr   *Expr
+	value  func([]float64) float64
+	fVar   func([]autodiff.Var) autodiff.Var
+	fHyper func([]autodiff.HyperDual) autodiff.HyperDual
+}
+
+// NewObjective создает целевую функцию из разобранного выражения.
+// Результат также реализует common_funcs.HessianVectorObjective.
+func NewObjective(e *Expr) common_funcs.HessianObjective {
+	return objective{
+		expr:   e,
+		value:  e.Compile(),
+		fVar:   CompileNumber[autodiff.Var](e),
+		fHyper: CompileNumber[autodiff.HyperDual](e),
+	}
+}
+
+// ParseObjective разбирает формулу и создает по ней целевую функцию.
+func ParseObjective(src string) (common_funcs.HessianObjective, error) {
+	e, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	if e.Dimension() == 0 {
+		return nil, fmt.Errorf("формула %q не содержит переменных", src)
+	}
+	return NewObjective(e), nil
+}
+
+// Load создает целевую функцию из формулы или из файла с формулой (см. ReadFormulaFile).
+// Если не задано ни то, ни другое, возвращает nil без ошибки.
+func Load(formula, file string) (common_funcs.HessianObjective, error) {
+	if file != "" {
+		var err error
+		if formula, err = ReadFormulaFile(file); err != nil {
+			return nil, err
+		}
+	}
+	if strings.TrimSpace(formula) == "" {
+		return nil, nil
+	}
+	return ParseObjective(formula)
+}
+
+// ReadFormulaFile читает формулу из файла, пропуская строки-комментарии с '#'.
+func ReadFormulaFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " "), nil
+}
+
+func (o objective) Value(x []float64) float64 {
+	checkDim(o.expr, x)
+	return o.value(x)
+}
+
+func (o objective) Gradient(x []float64) []float64 {
+	checkDim(o.expr, x)
+	_, grad := autodiff.ReverseGradient(o.fVar, x)
+	return grad
+}
+
+func (o objective) Hessian(x []float64) common_funcs.Matrix {
+	checkDim(o.expr, x)
+	return autodiff.Hessian(o.fHyper, x)
+}
+
+func (o objective) HessianVector(x, v []float64) []float64 {
+	checkDim(o.expr, x)
+	return autodiff.HessianVectorProduct(o.fVar, x, v)
+}
+
+func (o objective) Dimension() int {
+	return o.expr.Dimension()
+}
+
+// checkDim проверяет размерность входного вектора.
+func checkDim(e *Expr, x []float64) {
+	if len(x) != e.Dimension() {
+		panic(fmt.Sprintf("Целевая функция ожидает %d-мерный вектор, получено: %d", e.Dimension(), len(x)))
+	}
+}
+
+// symbolicObjective - целевая функция, у которой градиент и Гессиан
+// вычисляются по точным символьным производным формулы.
+type symbolicObjective struct {
+	expr *Expr
+	f    func([]float64) float64
+	grad []func([]float64) float64
+	hess [][]func([]float64) float64
+}
+
+// NewSymbolicObjective создает целевую функцию по выражению e, дифференцируя его
+// символьно: градиент и Гессиан компилируются так же, как сама функция.
+func NewSymbolicObjective(e *Expr) common_funcs.HessianObjective {
+	n := e.Dimension()
+	o := symbolicObjective{expr: e, f: e.Compile()}
+	gradExpr := e.Gradient()
+	o.grad = make([]func([]float64) float64, n)
+	o.hess = make([][]func([]float64) float64, n)
+	for i := 0; i < n; i++ {
+		o.grad[i] = gradExpr[i].Compile()
+		o.hess[i] = make([]func([]float64) float64, n)
+		for j := i; j < n; j++ {
+			o.hess[i][j] = gradExpr[i].Derivative(j).Compile()
+		}
+	}
+	return o
+}
+
+func (o symbolicObjective) Value(x []float64) float64 {
+	checkDim(o.expr, x)
+	return o.f(x)
+}
+
+func (o symbolicObjective) Gradient(x []float64) []float64 {
+	checkDim(o.expr, x)
+	grad := make([]float64, len(o.grad))
+	for i, g := range o.grad {
+		grad[i] = g(x)
+	}
+	return grad
+}
+
+func (o symbolicObjective) Hessian(x []float64) common_funcs.Matrix {
+	checkDim(o.expr, x)
+	n := len(o.hess)
+	hess := common_funcs.NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			hess[i][j] = o.hess[i][j](x)
+			hess[j][i] = hess[i][j] // Симметричная
+		}
+	}
+	return hess
+}
+
+func (o symbolicObjective) Dimension() int {
+	return o.expr.Dimension()
+}
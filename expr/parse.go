@@ -0,0 +1,356 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError - ошибка разбора с позицией в исходной строке.
+type SyntaxError struct {
+	Pos int    // Позиция (в символах) от начала строки
+	Msg string // Описание ошибки
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("ошибка разбора в позиции %d: %s", e.Pos+1, e.Msg)
+}
+
+// Виды лексем
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNum
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+// lex разбивает строку на лексемы.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// Экспоненциальная запись: 1e-6, 2.5E+3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("некорректное число %q", text)}
+			}
+			tokens = append(tokens, token{kind: tokNum, text: text, num: v, pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start})
+		case r == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			tokens = append(tokens, token{kind: tokOp, text: "^", pos: i})
+			i += 2
+		case strings.ContainsRune("+-*/^", r):
+			tokens = append(tokens, token{kind: tokOp, text: string(r), pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("неожиданный символ %q", r)}
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// parser - рекурсивный спуск по грамматике:
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/") unary }
+//	unary  = ("-" | "+") unary | power
+//	power  = primary [ "^" unary ]
+//	primary = number | ident | ident "(" expr ")" | "(" expr ")"
+//
+// Степень правоассоциативна и связывает сильнее унарного минуса: -x^2 = -(x^2).
+type parser struct {
+	tokens []token
+	pos    int
+	vars   map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops string) bool {
+	t := p.peek()
+	return t.kind == tokOp && strings.Contains(ops, t.text)
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+-") {
+		op := p.next().text[0]
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: op, L: left, R: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTerm() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*/") {
+		op := p.next().text[0]
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: op, L: left, R: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.isOp("+-") {
+		op := p.next().text[0]
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if op == '+' {
+			return x, nil
+		}
+		return Unary{Op: '-', X: x}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *parser) parsePower() (Node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		p.next()
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: '^', L: base, R: exp}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		return Num{Value: t.num}, nil
+	case tokLParen:
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, &SyntaxError{Pos: p.peek().pos, Msg: "ожидалась ')'"}
+		}
+		p.next()
+		return x, nil
+	case tokIdent:
+		if isFunction(t.text) {
+			if p.peek().kind != tokLParen {
+				return nil, &SyntaxError{Pos: p.peek().pos, Msg: fmt.Sprintf("ожидалась '(' после %s", t.text)}
+			}
+			p.next()
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if p.peek().kind != tokRParen {
+				return nil, &SyntaxError{Pos: p.peek().pos, Msg: "ожидалась ')'"}
+			}
+			p.next()
+			return Call{Func: functions[strings.ToLower(t.text)], Arg: arg}, nil
+		}
+		if c, ok := constants[t.text]; ok {
+			return Num{Value: c}, nil
+		}
+		if k, ok := xIndex(t.text); ok && k > MaxVarIndex {
+			return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("номер переменной %s больше допустимого %d", t.text, MaxVarIndex)}
+		}
+		p.vars[t.text] = true
+		return Var{Name: t.text, Index: -1}, nil
+	case tokEOF:
+		return nil, &SyntaxError{Pos: t.pos, Msg: "неожиданный конец выражения"}
+	default:
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("неожиданная лексема %q", t.text)}
+	}
+}
+
+// Parse разбирает выражение. Порядок переменных определяется автоматически:
+// если все переменные имеют вид x1, x2, ..., xN, то xK соответствует
+// координате K-1, а размерность равна наибольшему номеру (пропущенные номера
+// допустимы, номер не больше MaxVarIndex). Иначе переменные упорядочиваются по имени.
+func Parse(src string) (*Expr, error) {
+	return parse(src, nil)
+}
+
+// ParseWithVars разбирает выражение с явно заданным порядком переменных.
+// Переменные, не входящие в vars, считаются ошибкой.
+func ParseWithVars(src string, vars []string) (*Expr, error) {
+	if vars == nil {
+		vars = []string{}
+	}
+	return parse(src, vars)
+}
+
+func parse(src string, vars []string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, vars: map[string]bool{}}
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("лишняя лексема %q", t.text)}
+	}
+
+	if vars == nil {
+		vars = orderVars(p.vars)
+	}
+	index := make(map[string]int, len(vars))
+	for i, name := range vars {
+		if name != "" {
+			index[name] = i
+		}
+	}
+	for name := range p.vars {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("неизвестная переменная %q", name)
+		}
+	}
+	return &Expr{Root: resolve(root, index), Vars: vars}, nil
+}
+
+// MaxVarIndex - наибольший допустимый номер K в имени переменной xK. Размерность
+// функции равна наибольшему номеру, поэтому без ограничения формула с x999999999
+// потребовала бы гигабайты памяти под пустые координаты.
+const MaxVarIndex = 100000
+
+// orderVars упорядочивает найденные переменные (см. Parse).
+// Пропущенные номера в схеме x1..xN дают пустые имена.
+func orderVars(found map[string]bool) []string {
+	maxIdx := 0
+	numbered := true
+	for name := range found {
+		k, ok := xIndex(name)
+		if !ok {
+			numbered = false
+			break
+		}
+		if k > maxIdx {
+			maxIdx = k
+		}
+	}
+	if numbered {
+		vars := make([]string, maxIdx)
+		for name := range found {
+			k, _ := xIndex(name)
+			vars[k-1] = name
+		}
+		return vars
+	}
+	vars := make([]string, 0, len(found))
+	for name := range found {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+// xIndex возвращает K для имени вида xK (K >= 1).
+func xIndex(name string) (int, bool) {
+	if len(name) < 2 || name[0] != 'x' {
+		return 0, false
+	}
+	k, err := strconv.Atoi(name[1:])
+	if err != nil || k < 1 || strconv.Itoa(k) != name[1:] {
+		return 0, false
+	}
+	return k, true
+}
+
+// resolve проставляет переменным индексы координат.
+func resolve(n Node, index map[string]int) Node {
+	switch n := n.(type) {
+	case Var:
+		n.Index = index[n.Name]
+		return n
+	case Unary:
+		n.X = resolve(n.X, index)
+		return n
+	case Binary:
+		n.L = resolve(n.L, index)
+		n.R = resolve(n.R, index)
+		return n
+	case Call:
+		n.Arg = resolve(n.Arg, index)
+		return n
+	default:
+		return n
+	}
+}
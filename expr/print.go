@@ -0,0 +1,214 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Приоритеты операций для расстановки скобок при печати
+const (
+	precSum   = 1 // + -
+	precProd  = 2 // * /
+	precUnary = 3 // унарный минус и отрицательные числа
+	precPow   = 4 // ^
+	precAtom  = 5 // числа, переменные, вызовы функций
+)
+
+func prec(n Node) int {
+	switch n := n.(type) {
+	case Num:
+		if n.Value < 0 {
+			return precUnary
+		}
+		return precAtom
+	case Unary:
+		return precUnary
+	case Binary:
+		switch n.Op {
+		case '+', '-':
+			return precSum
+		case '*', '/':
+			return precProd
+		default:
+			return precPow
+		}
+	default:
+		return precAtom
+	}
+}
+
+// formatNum печатает число в кратчайшей точной записи.
+func formatNum(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// String печатает выражение в текстовом виде, понятном Parse.
+func String(n Node) string {
+	var sb strings.Builder
+	writePlain(&sb, n)
+	return sb.String()
+}
+
+func writePlainParen(sb *strings.Builder, n Node, paren bool) {
+	if paren {
+		sb.WriteByte('(')
+		writePlain(sb, n)
+		sb.WriteByte(')')
+		return
+	}
+	writePlain(sb, n)
+}
+
+func writePlain(sb *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case Num:
+		sb.WriteString(formatNum(n.Value))
+	case Var:
+		sb.WriteString(n.Name)
+	case Unary:
+		sb.WriteByte('-')
+		writePlainParen(sb, n.X, prec(n.X) < precProd || prec(n.X) == precUnary)
+	case Binary:
+		p := prec(n)
+		switch n.Op {
+		case '^':
+			writePlainParen(sb, n.L, prec(n.L) <= precPow)
+			sb.WriteByte('^')
+			writePlainParen(sb, n.R, prec(n.R) < precPow)
+		case '+', '-':
+			writePlainParen(sb, n.L, prec(n.L) < p)
+			sb.WriteString(" " + string(n.Op) + " ")
+			writePlainParen(sb, n.R, prec(n.R) <= p && n.Op == '-')
+		default:
+			writePlainParen(sb, n.L, prec(n.L) < p)
+			sb.WriteByte(n.Op)
+			writePlainParen(sb, n.R, prec(n.R) <= p)
+		}
+	case Call:
+		sb.WriteString(n.Func + "(")
+		writePlain(sb, n.Arg)
+		sb.WriteByte(')')
+	}
+}
+
+// LaTeX печатает выражение в нотации LaTeX (для вставки в отчеты).
+// Переменные вида x1 печатаются с индексом: x_{1}.
+func LaTeX(n Node) string {
+	var sb strings.Builder
+	writeLaTeX(&sb, n)
+	return sb.String()
+}
+
+func writeLaTeXParen(sb *strings.Builder, n Node, paren bool) {
+	if paren {
+		sb.WriteString(`\left(`)
+		writeLaTeX(sb, n)
+		sb.WriteString(`\right)`)
+		return
+	}
+	writeLaTeX(sb, n)
+}
+
+// latexNum печатает число, заменяя экспоненциальную запись на 10^{k}.
+func latexNum(v float64) string {
+	s := formatNum(v)
+	if i := strings.IndexByte(s, 'e'); i >= 0 {
+		exp, _ := strconv.Atoi(s[i+1:]) // Без ведущих нулей и знака "+"
+		pow10 := `10^{` + strconv.Itoa(exp) + `}`
+		if s[:i] == "1" {
+			return pow10
+		}
+		return s[:i] + ` \cdot ` + pow10
+	}
+	return s
+}
+
+// latexVar печатает имя переменной, отделяя числовой суффикс в индекс.
+func latexVar(name string) string {
+	i := len(name)
+	for i > 0 && name[i-1] >= '0' && name[i-1] <= '9' {
+		i--
+	}
+	if i == 0 || i == len(name) {
+		return name
+	}
+	return name[:i] + "_{" + name[i:] + "}"
+}
+
+func writeLaTeX(sb *strings.Builder, n Node) {
+	switch n := n.(type) {
+	case Num:
+		sb.WriteString(latexNum(n.Value))
+	case Var:
+		sb.WriteString(latexVar(n.Name))
+	case Unary:
+		sb.WriteByte('-')
+		writeLaTeXParen(sb, n.X, prec(n.X) < precProd || prec(n.X) == precUnary)
+	case Binary:
+		p := prec(n)
+		switch n.Op {
+		case '^':
+			writeLaTeXParen(sb, n.L, prec(n.L) <= precPow)
+			sb.WriteString("^{")
+			writeLaTeX(sb, n.R)
+			sb.WriteByte('}')
+		case '/':
+			sb.WriteString(`\frac{`)
+			writeLaTeX(sb, n.L)
+			sb.WriteString("}{")
+			writeLaTeX(sb, n.R)
+			sb.WriteByte('}')
+		case '+', '-':
+			writeLaTeXParen(sb, n.L, prec(n.L) < p)
+			sb.WriteString(" " + string(n.Op) + " ")
+			writeLaTeXParen(sb, n.R, prec(n.R) <= p && n.Op == '-')
+		default:
+			writeLaTeXParen(sb, n.L, prec(n.L) < p)
+			// Числовой коэффициент перед буквенным множителем пишется без знака умножения
+			if _, isNum := n.L.(Num); isNum && !startsWithNum(n.R) {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteString(` \cdot `)
+			}
+			writeLaTeXParen(sb, n.R, prec(n.R) <= p)
+		}
+	case Call:
+		if n.Func == "sqrt" {
+			sb.WriteString(`\sqrt{`)
+			writeLaTeX(sb, n.Arg)
+			sb.WriteByte('}')
+			return
+		}
+		name := n.Func
+		if name == "log" {
+			name = "ln"
+		}
+		sb.WriteString(`\` + name + `\left(`)
+		writeLaTeX(sb, n.Arg)
+		sb.WriteString(`\right)`)
+	}
+}
+
+// startsWithNum сообщает, начинается ли печатная запись узла с цифры.
+func startsWithNum(n Node) bool {
+	switch n := n.(type) {
+	case Num:
+		return true
+	case Binary:
+		if n.Op == '/' {
+			return false
+		}
+		return prec(n.L) >= prec(n) && startsWithNum(n.L)
+	}
+	return false
+}
+
+// String печатает выражение в текстовом виде.
+func (e *Expr) String() string {
+	return String(e.Root)
+}
+
+// LaTeX печатает выражение в нотации LaTeX.
+func (e *Expr) LaTeX() string {
+	return LaTeX(e.Root)
+}
@@ -0,0 +1,249 @@
+package expr
+
+import "math"
+
+// Simplify упрощает выражение: сворачивает константы, убирает нейтральные
+// элементы (x+0, 1·x, x^1), приводит подобные слагаемые (2·x + 3·x = 5·x)
+// и объединяет степени одного основания в произведениях (x·x² = x³).
+// Коэффициент произведения выносится вперед, знаменатель - в дробь.
+func Simplify(n Node) Node {
+	switch n := n.(type) {
+	case Unary:
+		return canonicalSum(Unary{Op: '-', X: Simplify(n.X)})
+	case Binary:
+		l, r := Simplify(n.L), Simplify(n.R)
+		switch n.Op {
+		case '+', '-':
+			return canonicalSum(Binary{Op: n.Op, L: l, R: r})
+		case '*', '/':
+			return canonicalProduct(Binary{Op: n.Op, L: l, R: r})
+		default:
+			if c, ok := constValue(l); ok && c == 1 {
+				return Num{Value: 1}
+			}
+			if _, ok := constValue(r); ok {
+				return canonicalProduct(Binary{Op: '^', L: l, R: r})
+			}
+			return Binary{Op: '^', L: l, R: r}
+		}
+	case Call:
+		arg := Simplify(n.Arg)
+		if c, ok := constValue(arg); ok {
+			return Num{Value: floatFunc(n.Func)(c)}
+		}
+		// exp(ln u) = u и ln(exp u) = u
+		if inner, ok := arg.(Call); ok {
+			if (n.Func == "exp" && inner.Func == "log") || (n.Func == "log" && inner.Func == "exp") {
+				return inner.Arg
+			}
+		}
+		return Call{Func: n.Func, Arg: arg}
+	default:
+		return n
+	}
+}
+
+// term - слагаемое суммы: coef · rest (rest == nil для константы).
+type term struct {
+	coef    float64
+	factors []factor
+	key     string
+}
+
+// factor - множитель произведения: base^exp с постоянным показателем.
+type factor struct {
+	base Node
+	exp  float64
+	key  string
+}
+
+// flattenSum раскладывает сумму на слагаемые, домножая их на sign.
+func flattenSum(n Node, sign float64, terms []term) []term {
+	switch n := n.(type) {
+	case Binary:
+		switch n.Op {
+		case '+':
+			return flattenSum(n.R, sign, flattenSum(n.L, sign, terms))
+		case '-':
+			return flattenSum(n.R, -sign, flattenSum(n.L, sign, terms))
+		}
+	case Unary:
+		return flattenSum(n.X, -sign, terms)
+	}
+	coef, factors := flattenProduct(n)
+	t := term{coef: sign * coef, factors: factors, key: String(buildProduct(1, factors))}
+	if len(factors) == 0 {
+		t.key = ""
+	}
+	for k := range terms {
+		if terms[k].key == t.key {
+			terms[k].coef += t.coef
+			return terms
+		}
+	}
+	return append(terms, t)
+}
+
+// canonicalSum приводит подобные слагаемые и собирает сумму заново.
+// Порядок слагаемых сохраняется, константа ставится в конец.
+func canonicalSum(n Node) Node {
+	terms := flattenSum(n, 1, nil)
+	var res Node
+	constant := 0.0
+	for _, t := range terms {
+		if t.key == "" {
+			constant += t.coef
+			continue
+		}
+		if t.coef == 0 {
+			continue
+		}
+		res = appendTerm(res, t.coef, buildProduct(math.Abs(t.coef), t.factors))
+	}
+	if constant != 0 || res == nil {
+		res = appendTerm(res, constant, Num{Value: math.Abs(constant)})
+	}
+	return res
+}
+
+// appendTerm добавляет к сумме res слагаемое abs со знаком sign.
+func appendTerm(res Node, sign float64, abs Node) Node {
+	switch {
+	case res == nil && sign < 0:
+		return negate(abs)
+	case res == nil:
+		return abs
+	case sign < 0:
+		return sub(res, abs)
+	default:
+		return add(res, abs)
+	}
+}
+
+// negate возвращает -n, сворачивая знак в числовую константу.
+func negate(n Node) Node {
+	if c, ok := n.(Num); ok {
+		return Num{Value: -c.Value}
+	}
+	return Unary{Op: '-', X: n}
+}
+
+// flattenProduct раскладывает произведение на числовой коэффициент и множители,
+// объединяя степени одинаковых оснований.
+func flattenProduct(n Node) (float64, []factor) {
+	coef := 1.0
+	var factors []factor
+	var walk func(n Node, exp float64)
+	walk = func(n Node, exp float64) {
+		switch m := n.(type) {
+		case Num:
+			coef *= math.Pow(m.Value, exp)
+			return
+		case Unary:
+			if exp == math.Trunc(exp) {
+				if math.Mod(exp, 2) != 0 {
+					coef = -coef
+				}
+				walk(m.X, exp)
+				return
+			}
+		case Binary:
+			switch m.Op {
+			case '*':
+				walk(m.L, exp)
+				walk(m.R, exp)
+				return
+			case '/':
+				walk(m.L, exp)
+				walk(m.R, -exp)
+				return
+			case '^':
+				if p, ok := m.R.(Num); ok {
+					// (u^p)^e = u^(pe) верно для целого e
+					if exp == math.Trunc(exp) || (p.Value == math.Trunc(p.Value) && isPositiveBase(m.L)) {
+						walk(m.L, p.Value*exp)
+						return
+					}
+				}
+			}
+		}
+		key := String(n)
+		for k := range factors {
+			if factors[k].key == key {
+				factors[k].exp += exp
+				return
+			}
+		}
+		factors = append(factors, factor{base: n, exp: exp, key: key})
+	}
+	walk(n, 1)
+	// Убираем множители с нулевой степенью (x^0 = 1)
+	kept := factors[:0]
+	for _, f := range factors {
+		if f.exp != 0 {
+			kept = append(kept, f)
+		}
+	}
+	return coef, kept
+}
+
+// isPositiveBase сообщает, что основание заведомо положительно (exp(u) или sqrt(u)),
+// поэтому степени можно перемножать и при нецелом показателе.
+func isPositiveBase(n Node) bool {
+	c, ok := n.(Call)
+	return ok && (c.Func == "exp" || c.Func == "sqrt")
+}
+
+// canonicalProduct сворачивает коэффициенты и степени произведения.
+// Произведение, ставшее суммой с отрицательным знаком, остается унарным минусом.
+func canonicalProduct(n Node) Node {
+	coef, factors := flattenProduct(n)
+	res := buildProduct(math.Abs(coef), factors)
+	if coef < 0 {
+		return negate(res)
+	}
+	return res
+}
+
+// buildProduct собирает coef · f₁^e₁ · f₂^e₂ ..., вынося множители
+// с отрицательными степенями в знаменатель. coef должен быть неотрицательным.
+func buildProduct(coef float64, factors []factor) Node {
+	if coef == 0 {
+		return Num{Value: 0}
+	}
+	var num, den Node
+	if coef != 1 {
+		num = Num{Value: coef}
+	}
+	for _, f := range factors {
+		if f.exp > 0 {
+			num = mulNode(num, powNode(f.base, f.exp))
+		} else {
+			den = mulNode(den, powNode(f.base, -f.exp))
+		}
+	}
+	if num == nil {
+		num = Num{Value: 1}
+	}
+	if den == nil {
+		return num
+	}
+	return div(num, den)
+}
+
+func mulNode(acc, f Node) Node {
+	if acc == nil {
+		return f
+	}
+	return mul(acc, f)
+}
+
+func powNode(base Node, exp float64) Node {
+	if exp == 1 {
+		return base
+	}
+	if exp == 0.5 {
+		return Call{Func: "sqrt", Arg: base}
+	}
+	return pow(base, Num{Value: exp})
+}
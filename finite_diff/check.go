@@ -0,0 +1,163 @@
+package finite_diff
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// CheckOptions - параметры проверки согласованности производных.
+type CheckOptions struct {
+	Scheme Scheme  // Схема для эталонных численных производных
+	Tol    float64 // Допустимая относительная ошибка компоненты
+	SymTol float64 // Допустимая относительная несимметричность Гессиана
+}
+
+// DefaultCheckOptions - параметры проверки по умолчанию.
+var DefaultCheckOptions = CheckOptionsFor(Richardson)
+
+// DefaultTol возвращает допуск по умолчанию для схемы. Он согласован с порядком
+// погрешности: Гессиан по значениям функции правой разностью (O(h)) верен лишь
+// до 1e-4..1e-3, центральной (O(h²)) - до 1e-6, поэтому единый допуск 1e-6
+// отвергал бы правильные аналитические производные.
+func DefaultTol(s Scheme) float64 {
+	switch s {
+	case Forward:
+		return 1e-3
+	case Central:
+		return 1e-5
+	default:
+		return 1e-6
+	}
+}
+
+// CheckOptionsFor возвращает параметры проверки по умолчанию для схемы s.
+func CheckOptionsFor(s Scheme) CheckOptions {
+	return CheckOptions{Scheme: s, Tol: DefaultTol(s), SymTol: 1e-10}
+}
+
+// Mismatch - расхождение одной компоненты аналитической и численной производной.
+type Mismatch struct {
+	Kind     string  // Что сравнивалось: "градиент", "Гессиан", "Гессиан/Якоби градиента", "симметрия"
+	I, J     int     // Индексы компоненты (J = -1 для градиента)
+	Analytic float64 // Аналитическое значение
+	Numeric  float64 // Эталонное значение
+	RelErr   float64 // Относительная ошибка
+}
+
+// PointReport - результат проверки в одной точке.
+type PointReport struct {
+	Point         []float64
+	MaxGradErr    float64    // Максимальная относительная ошибка градиента
+	MaxHessErr    float64    // Максимальная относительная ошибка Гессиана по значениям функции
+	MaxJacErr     float64    // Максимальная относительная ошибка Гессиана относительно Якоби градиента
+	MaxAsymmetry  float64    // Максимальная относительная несимметричность Гессиана
+	HasHessian    bool       // Проверялся ли Гессиан
+	Mismatches    []Mismatch // Компоненты, превысившие допуск
+	GradRelErrors []float64  // Относительные ошибки по компонентам градиента
+}
+
+// OK сообщает, что в точке не найдено расхождений.
+func (r PointReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// relErr - относительная ошибка с защитой от деления на ноль около нуля.
+func relErr(analytic, numeric float64) float64 {
+	return math.Abs(analytic-numeric) / math.Max(1, math.Abs(numeric))
+}
+
+// CheckDerivatives сравнивает градиент obj (и Гессиан, если obj его вычисляет)
+// с конечными разностями в каждой из точек points. Гессиан сверяется и с
+// разностями значений функции, и с матрицей Якоби аналитического градиента,
+// а также проверяется на симметричность.
+func CheckDerivatives(obj common_funcs.Objective, points [][]float64, opts CheckOptions) []PointReport {
+	reports := make([]PointReport, 0, len(points))
+	for _, x := range points {
+		rep := PointReport{Point: x}
+
+		grad := obj.Gradient(x)
+		numGrad := Gradient(obj.Value, x, opts.Scheme)
+		rep.GradRelErrors = make([]float64, len(x))
+		for i := range grad {
+			e := relErr(grad[i], numGrad[i])
+			rep.GradRelErrors[i] = e
+			rep.MaxGradErr = math.Max(rep.MaxGradErr, e)
+			if e > opts.Tol {
+				rep.Mismatches = append(rep.Mismatches, Mismatch{Kind: "градиент", I: i, J: -1, Analytic: grad[i], Numeric: numGrad[i], RelErr: e})
+			}
+		}
+
+		if hobj, ok := obj.(common_funcs.HessianObjective); ok {
+			rep.HasHessian = true
+			hess := hobj.Hessian(x)
+			numHess := Hessian(obj.Value, x, opts.Scheme)
+			jac := Jacobian(obj.Gradient, x, opts.Scheme)
+			n := len(x)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					e := relErr(hess[i][j], numHess[i][j])
+					rep.MaxHessErr = math.Max(rep.MaxHessErr, e)
+					if e > opts.Tol {
+						rep.Mismatches = append(rep.Mismatches, Mismatch{Kind: "Гессиан", I: i, J: j, Analytic: hess[i][j], Numeric: numHess[i][j], RelErr: e})
+					}
+					e = relErr(hess[i][j], jac[i][j])
+					rep.MaxJacErr = math.Max(rep.MaxJacErr, e)
+					if e > opts.Tol {
+						rep.Mismatches = append(rep.Mismatches, Mismatch{Kind: "Гессиан/Якоби градиента", I: i, J: j, Analytic: hess[i][j], Numeric: jac[i][j], RelErr: e})
+					}
+					if j > i {
+						e := relErr(hess[i][j], hess[j][i])
+						rep.MaxAsymmetry = math.Max(rep.MaxAsymmetry, e)
+						if e > opts.SymTol {
+							rep.Mismatches = append(rep.Mismatches, Mismatch{Kind: "симметрия", I: i, J: j, Analytic: hess[i][j], Numeric: hess[j][i], RelErr: e})
+						}
+					}
+				}
+			}
+		}
+		reports = append(reports, rep)
+	}
+	return reports
+}
+
+// RandomPoints генерирует count случайных точек размерности dim,
+// равномерно распределенных в кубе [-radius, radius]^dim.
+func RandomPoints(rng *rand.Rand, count, dim int, radius float64) [][]float64 {
+	points := make([][]float64, count)
+	for k := range points {
+		points[k] = make([]float64, dim)
+		for i := range points[k] {
+			points[k][i] = radius * (2*rng.Float64() - 1)
+		}
+	}
+	return points
+}
+
+// PrintReports выводит отчет о проверке и возвращает true, если расхождений нет.
+func PrintReports(w io.Writer, reports []PointReport) bool {
+	allOK := true
+	for k, rep := range reports {
+		status := "OK"
+		if !rep.OK() {
+			status = "РАСХОЖДЕНИЕ"
+			allOK = false
+		}
+		fmt.Fprintf(w, "Точка %d %v: %s\n", k+1, rep.Point, status)
+		fmt.Fprintf(w, "  Относительные ошибки градиента по компонентам: %.2e\n", rep.GradRelErrors)
+		if rep.HasHessian {
+			fmt.Fprintf(w, "  Макс. ошибка Гессиана: %.2e, относительно Якоби градиента: %.2e, несимметричность: %.2e\n",
+				rep.MaxHessErr, rep.MaxJacErr, rep.MaxAsymmetry)
+		}
+		for _, m := range rep.Mismatches {
+			idx := fmt.Sprintf("[%d]", m.I)
+			if m.J >= 0 {
+				idx = fmt.Sprintf("[%d][%d]", m.I, m.J)
+			}
+			fmt.Fprintf(w, "  %s%s: %.10g против %.10g (отн. ошибка %.2e)\n", m.Kind, idx, m.Analytic, m.Numeric, m.RelErr)
+		}
+	}
+	return allOK
+}
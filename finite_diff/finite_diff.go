@@ -0,0 +1,215 @@
+// Package finite_diff вычисляет градиент и Гессиан конечными разностями.
+// Используется, когда аналитические GradF и Hessian недоступны: обертки
+// NewObjective и WithHessian дают готовые целевые функции для newtonMethod,
+// quasiNewtonRank1 и conjugateGradient.
+package finite_diff
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// Scheme - разностная схема.
+type Scheme int
+
+const (
+	Forward    Scheme = iota // Правая разность, погрешность O(h)
+	Central                  // Центральная разность, погрешность O(h²)
+	Richardson               // Экстраполяция Ричардсона центральных разностей, погрешность O(h⁴)
+)
+
+// String возвращает название схемы для вывода.
+func (s Scheme) String() string {
+	switch s {
+	case Forward:
+		return "правая разность"
+	case Central:
+		return "центральная разность"
+	case Richardson:
+		return "экстраполяция Ричардсона"
+	default:
+		return "неизвестная схема"
+	}
+}
+
+// Машинная точность для float64
+var machEps = math.Nextafter(1, 2) - 1
+
+// baseStep возвращает относительный шаг, балансирующий погрешность схемы
+// и ошибку округления: для производной порядка order схема с погрешностью
+// O(h^p) оптимальна при h ~ eps^(1/(p+order)).
+func baseStep(scheme Scheme, order int) float64 {
+	p := 1.0
+	switch scheme {
+	case Central:
+		p = 2
+	case Richardson:
+		p = 4
+	}
+	return math.Pow(machEps, 1/(p+float64(order)))
+}
+
+// stepFor масштабирует шаг по величине координаты xi и делает его точно
+// представимым: (xi + h) - xi == h, чтобы не вносить лишнюю ошибку в знаменатель.
+func stepFor(xi, base float64) float64 {
+	h := base * math.Max(1, math.Abs(xi))
+	tmp := xi + h
+	return tmp - xi
+}
+
+// diffVec дифференцирует вектор-функцию g(t) в точке t = 0 с шагом h.
+func diffVec(g func(t float64) []float64, h float64, scheme Scheme) []float64 {
+	switch scheme {
+	case Forward:
+		return common_funcs.ScalarMult(1/h, common_funcs.VectorSub(g(h), g(0)))
+	case Central:
+		return centralVec(g, h)
+	case Richardson:
+		// R = (4·D(h/2) - D(h)) / 3 уничтожает член O(h²) центральной разности
+		d1 := centralVec(g, h)
+		d2 := centralVec(g, h/2)
+		return common_funcs.ScalarMult(1.0/3, common_funcs.VectorSub(common_funcs.ScalarMult(4, d2), d1))
+	default:
+		panic("Неизвестная разностная схема")
+	}
+}
+
+// centralVec - центральная разность (g(h) - g(-h)) / 2h.
+func centralVec(g func(t float64) []float64, h float64) []float64 {
+	return common_funcs.ScalarMult(1/(2*h), common_funcs.VectorSub(g(h), g(-h)))
+}
+
+// shifted возвращает копию x со сдвигом координаты i на t.
+func shifted(x []float64, i int, t float64) []float64 {
+	y := make([]float64, len(x))
+	copy(y, x)
+	y[i] += t
+	return y
+}
+
+// Gradient вычисляет градиент f в точке x по выбранной схеме с автоматическим шагом.
+func Gradient(f func([]float64) float64, x []float64, scheme Scheme) []float64 {
+	base := baseStep(scheme, 1)
+	grad := make([]float64, len(x))
+	for i := range x {
+		h := stepFor(x[i], base)
+		d := diffVec(func(t float64) []float64 {
+			return []float64{f(shifted(x, i, t))}
+		}, h, scheme)
+		grad[i] = d[0]
+	}
+	return grad
+}
+
+// Jacobian вычисляет матрицу Якоби вектор-функции grad в точке x (по столбцам).
+func Jacobian(grad func([]float64) []float64, x []float64, scheme Scheme) common_funcs.Matrix {
+	n := len(x)
+	base := baseStep(scheme, 1)
+	jac := common_funcs.NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		h := stepFor(x[j], base)
+		col := diffVec(func(t float64) []float64 {
+			return grad(shifted(x, j, t))
+		}, h, scheme)
+		for i := 0; i < n; i++ {
+			jac[i][j] = col[i]
+		}
+	}
+	return jac
+}
+
+// JacobianHessian вычисляет Гессиан как матрицу Якоби градиента grad в точке x
+// и симметризует результат: H = (J + Jᵀ) / 2.
+func JacobianHessian(grad func([]float64) []float64, x []float64, scheme Scheme) common_funcs.Matrix {
+	return symmetrize(Jacobian(grad, x, scheme))
+}
+
+// Hessian вычисляет Гессиан f в точке x только по значениям функции.
+func Hessian(f func([]float64) float64, x []float64, scheme Scheme) common_funcs.Matrix {
+	n := len(x)
+	base := baseStep(scheme, 2)
+	h := make([]float64, n)
+	for i := range x {
+		h[i] = stepFor(x[i], base)
+	}
+	switch scheme {
+	case Forward:
+		return forwardHessian(f, x, h)
+	case Central:
+		return centralHessian(f, x, h)
+	case Richardson:
+		// Для второй разности ошибка тоже раскладывается по четным степеням h
+		half := common_funcs.ScalarMult(0.5, h)
+		h1 := centralHessian(f, x, h)
+		h2 := centralHessian(f, x, half)
+		return common_funcs.MatrixAdd(common_funcs.MatrixScalarMult(4.0/3, h2), common_funcs.MatrixScalarMult(-1.0/3, h1))
+	default:
+		panic("Неизвестная разностная схема")
+	}
+}
+
+// forwardHessian: Hᵢⱼ ≈ (f(x+hᵢeᵢ+hⱼeⱼ) - f(x+hᵢeᵢ) - f(x+hⱼeⱼ) + f(x)) / (hᵢhⱼ).
+func forwardHessian(f func([]float64) float64, x, h []float64) common_funcs.Matrix {
+	n := len(x)
+	f0 := f(x)
+	fi := make([]float64, n)
+	for i := range x {
+		fi[i] = f(shifted(x, i, h[i]))
+	}
+	hess := common_funcs.NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			fij := f(shifted(shifted(x, i, h[i]), j, h[j]))
+			hess[i][j] = (fij - fi[i] - fi[j] + f0) / (h[i] * h[j])
+			hess[j][i] = hess[i][j] // Симметричная
+		}
+	}
+	return hess
+}
+
+// centralHessian - центральные вторые разности:
+// Hᵢᵢ ≈ (f(x+hᵢeᵢ) - 2f(x) + f(x-hᵢeᵢ)) / hᵢ²,
+// Hᵢⱼ ≈ (f(x+hᵢeᵢ+hⱼeⱼ) - f(x+hᵢeᵢ-hⱼeⱼ) - f(x-hᵢeᵢ+hⱼeⱼ) + f(x-hᵢeᵢ-hⱼeⱼ)) / (4hᵢhⱼ).
+func centralHessian(f func([]float64) float64, x, h []float64) common_funcs.Matrix {
+	n := len(x)
+	f0 := f(x)
+	hess := common_funcs.NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		hess[i][i] = (f(shifted(x, i, h[i])) - 2*f0 + f(shifted(x, i, -h[i]))) / (h[i] * h[i])
+		for j := i + 1; j < n; j++ {
+			fpp := f(shifted(shifted(x, i, h[i]), j, h[j]))
+			fpm := f(shifted(shifted(x, i, h[i]), j, -h[j]))
+			fmp := f(shifted(shifted(x, i, -h[i]), j, h[j]))
+			fmm := f(shifted(shifted(x, i, -h[i]), j, -h[j]))
+			hess[i][j] = (fpp - fpm - fmp + fmm) / (4 * h[i] * h[j])
+			hess[j][i] = hess[i][j] // Симметричная
+		}
+	}
+	return hess
+}
+
+// symmetrize возвращает (m + mᵀ) / 2.
+func symmetrize(m common_funcs.Matrix) common_funcs.Matrix {
+	n := len(m)
+	res := common_funcs.NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			res[i][j] = (m[i][j] + m[j][i]) / 2
+		}
+	}
+	return res
+}
+
+// HessianVector вычисляет произведение Гессиана на вектор v разностью градиентов
+// вдоль v: ∇²f(x)·v ≈ (∇f(x+hv) - ∇f(x-hv)) / 2h для центральной схемы.
+func HessianVector(grad func([]float64) []float64, x, v []float64, scheme Scheme) []float64 {
+	vNorm := common_funcs.VectorNorm(v)
+	if vNorm == 0 {
+		return make([]float64, len(x))
+	}
+	// Шаг подбирается относительно нормы x и длины направления v
+	h := baseStep(scheme, 1) * math.Max(1, common_funcs.VectorNorm(x)) / vNorm
+	return diffVec(func(t float64) []float64 {
+		return grad(common_funcs.VectorAdd(x, common_funcs.ScalarMult(t, v)))
+	}, h, scheme)
+}
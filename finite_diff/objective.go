@@ -0,0 +1,75 @@
+package finite_diff
+
+import (
+	"fmt"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// numericObjective - целевая функция, у которой известно только значение.
+type numericObjective struct {
+	dim    int
+	f      func([]float64) float64
+	scheme Scheme
+}
+
+// NewObjective создает целевую функцию размерности dim, градиент и Гессиан
+// которой вычисляются конечными разностями по значениям f.
+// Результат также реализует common_funcs.HessianVectorObjective.
+func NewObjective(dim int, f func([]float64) float64, scheme Scheme) common_funcs.HessianObjective {
+	return numericObjective{dim: dim, f: f, scheme: scheme}
+}
+
+func (o numericObjective) Value(x []float64) float64 {
+	checkDim(o.dim, x)
+	return o.f(x)
+}
+
+func (o numericObjective) Gradient(x []float64) []float64 {
+	checkDim(o.dim, x)
+	return Gradient(o.f, x, o.scheme)
+}
+
+func (o numericObjective) Hessian(x []float64) common_funcs.Matrix {
+	checkDim(o.dim, x)
+	return Hessian(o.f, x, o.scheme)
+}
+
+func (o numericObjective) HessianVector(x, v []float64) []float64 {
+	checkDim(o.dim, x)
+	return HessianVector(o.Gradient, x, v, o.scheme)
+}
+
+func (o numericObjective) Dimension() int {
+	return o.dim
+}
+
+// hessianObjective дополняет целевую функцию с аналитическим градиентом
+// численным Гессианом (матрицей Якоби градиента).
+type hessianObjective struct {
+	common_funcs.Objective
+	scheme Scheme
+}
+
+// WithHessian дополняет obj конечно-разностным Гессианом по его градиенту,
+// чтобы функцию без аналитического Гессиана можно было передать в newtonMethod.
+// Результат также реализует common_funcs.HessianVectorObjective.
+func WithHessian(obj common_funcs.Objective, scheme Scheme) common_funcs.HessianObjective {
+	return hessianObjective{Objective: obj, scheme: scheme}
+}
+
+func (o hessianObjective) Hessian(x []float64) common_funcs.Matrix {
+	checkDim(o.Dimension(), x)
+	return JacobianHessian(o.Gradient, x, o.scheme)
+}
+
+func (o hessianObjective) HessianVector(x, v []float64) []float64 {
+	checkDim(o.Dimension(), x)
+	return HessianVector(o.Gradient, x, v, o.scheme)
+}
+
+// checkDim проверяет размерность входного вектора.
+func checkDim(dim int, x []float64) {
+	if len(x) != dim {
+		panic(fmt.Sprintf("Целевая функция ожидает %d-мерный вектор, получено: %d", dim, len(x)))
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"os"
+)
+
+// steepestDescent реализует метод наискорейшего спуска: d = -grad,
+// шаг выбирается одномерным поиском.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// Возвращает найденную точку минимума и количество итераций.
+func steepestDescent(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch) ([]float64, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+
+	// Основной цикл метода
+	for iter < maxIter {
+		grad := obj.Gradient(x)
+
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			break
+		}
+
+		// Шаг по антиградиенту: x_next = x - alpha * grad
+		direction := common_funcs.ScalarMult(-1.0, grad)
+		alpha := lineSearch.Step(obj, x, direction, grad)
+		x = common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Метод наискорейшего спуска достиг максимального числа итераций.")
+	}
+	return x, iter // Возвращаем результат
+}
+
+// Оценка константы Липшица градиента L (шаг 1/L): начальная и предельная,
+// после которой подбор шага в gradientStep прекращается.
+const (
+	initialLipschitz = 1.0
+	maxLipschitz     = 1e20
+)
+
+// gradientStep делает шаг x - grad/L с подбором L: L удваивается, пока не
+// выполнено условие достаточного убывания f(x - grad/L) ≤ f(x) - ‖grad‖²/(2L),
+// которое гарантировано при L, не меньшей константы Липшица градиента.
+// Возвращает новую точку, значение в ней, найденную L и false, если условие
+// не выполнилось и при L > maxLipschitz (например, из-за ошибок округления).
+func gradientStep(obj common_funcs.Objective, x, grad []float64, fx, lipschitz float64) ([]float64, float64, float64, bool) {
+	gradNormSq := common_funcs.DotProduct(grad, grad)
+	for {
+		xNext := common_funcs.VectorAdd(x, common_funcs.ScalarMult(-1/lipschitz, grad))
+		fNext := obj.Value(xNext)
+		if fNext <= fx-gradNormSq/(2*lipschitz) {
+			return xNext, fNext, lipschitz, true
+		}
+		if lipschitz > maxLipschitz {
+			return xNext, fNext, lipschitz, false
+		}
+		lipschitz *= 2
+	}
+}
+
+// heavyBall реализует метод тяжелого шарика Поляка:
+// x_next = x - grad/L + momentum * (x - x_prev).
+// Шаг 1/L подбирается в gradientStep; если с учетом инерции функция возросла,
+// инерция сбрасывается (адаптивный рестарт) и делается обычный градиентный шаг.
+// Если подобрать шаг не удалось, метод останавливается.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// momentum - коэффициент инерции (0 ≤ momentum < 1).
+// Возвращает найденную точку минимума, количество итераций и количество рестартов.
+func heavyBall(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, momentum float64) ([]float64, int, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	xPrev := x
+	iter, restarts := 0, 0
+	fx := obj.Value(x)
+	lipschitz := initialLipschitz
+
+	// Основной цикл метода
+	for iter < maxIter {
+		grad := obj.Gradient(x)
+
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			break
+		}
+
+		// 1. Градиентный шаг с подбором L; L уменьшается, чтобы шаг мог расти
+		xGrad, fGrad, l, ok := gradientStep(obj, x, grad, fx, lipschitz/2)
+		lipschitz = l
+		if !ok {
+			fmt.Println("Метод тяжелого шарика: не удалось подобрать шаг, уменьшающий функцию.")
+			break
+		}
+
+		// 2. Добавляем инерцию; при возрастании функции отказываемся от нее
+		xNext := common_funcs.VectorAdd(xGrad, common_funcs.ScalarMult(momentum, common_funcs.VectorSub(x, xPrev)))
+		fNext := obj.Value(xNext)
+		if fNext > fx {
+			restarts++
+			xNext, fNext = xGrad, fGrad
+		}
+
+		xPrev, x, fx = x, xNext, fNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Метод тяжелого шарика достиг максимального числа итераций.")
+	}
+	return x, iter, restarts // Возвращаем результат
+}
+
+// nesterov реализует ускоренный градиентный метод Нестерова:
+// y = x + (t - 1)/t_next * (x - x_prev), x_next = y - grad(y)/L,
+// t_next = (1 + √(1 + 4t²)) / 2. Шаг 1/L подбирается в gradientStep в точке y.
+// Если функция возросла, последовательность t сбрасывается (рестарт
+// О'Донохью-Кандеса) и делается обычный градиентный шаг из x, что
+// восстанавливает монотонность на невыпуклых функциях. Если и для него
+// подобрать шаг не удалось, метод останавливается.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента в точке экстраполяции y).
+// maxIter - максимальное количество итераций.
+// Возвращает найденную точку минимума, количество итераций и количество рестартов.
+func nesterov(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int) ([]float64, int, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	xPrev := x
+	iter, restarts := 0, 0
+	fx := obj.Value(x)
+	t := 1.0
+	lipschitz := initialLipschitz
+
+	// Основной цикл метода
+	for iter < maxIter {
+		// 1. Точка экстраполяции y
+		tNext := (1 + math.Sqrt(1+4*t*t)) / 2
+		y := common_funcs.VectorAdd(x, common_funcs.ScalarMult((t-1)/tNext, common_funcs.VectorSub(x, xPrev)))
+		grad := obj.Gradient(y)
+
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			x = y
+			break
+		}
+
+		// 2. Градиентный шаг из y с подбором L
+		fy := obj.Value(y)
+		xNext, fNext, l, _ := gradientStep(obj, y, grad, fy, lipschitz/2)
+
+		// 3. Рестарт при возрастании функции: градиентный шаг из x без инерции
+		// (подбор L начинается заново, а не с оценки, завышенной в точке y)
+		if fNext > fx {
+			restarts++
+			var ok bool
+			xNext, fNext, l, ok = gradientStep(obj, x, obj.Gradient(x), fx, lipschitz/2)
+			if !ok {
+				fmt.Println("Ускоренный метод Нестерова: не удалось подобрать шаг, уменьшающий функцию.")
+				break
+			}
+			tNext = 1
+		}
+
+		lipschitz = l
+		xPrev, x, fx, t = x, xNext, fNext, tNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Ускоренный метод Нестерова достиг максимального числа итераций.")
+	}
+	return x, iter, restarts // Возвращаем результат
+}
+
+// bbVariant - формула шага Барзилая-Борвейна по s = x_next - x, y = grad_next - grad.
+type bbVariant int
+
+const (
+	BB1 bbVariant = iota // alpha = sᵀs / sᵀy (длинный шаг)
+	BB2                  // alpha = sᵀy / yᵀy (короткий шаг)
+)
+
+func (v bbVariant) String() string {
+	if v == BB2 {
+		return "BB2"
+	}
+	return "BB1"
+}
+
+// Границы шага Барзилая-Борвейна: при sᵀy ≤ 0 (отрицательная кривизна) или
+// вырожденных s, y шаг берется на границе.
+const (
+	bbMinStep = 1e-10
+	bbMaxStep = 1e10
+)
+
+// barzilaiBorwein реализует градиентный метод с шагом Барзилая-Борвейна
+// и немонотонным поиском Гриппо-Лампариелло-Лючиди (метод Рэйдана):
+// шаг alpha уменьшается вдвое, пока f(x - alpha*grad) > max(последние memory
+// значений f) - 1e-4*alpha*‖grad‖²; если шаг стал меньше bbMinStep, метод
+// останавливается. Сам шаг BB не гарантирует убывания f на
+// каждой итерации, но за счет этого метод сходится намного быстрее наискорейшего спуска.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// variant - формула шага (BB1 или BB2).
+// memory - глубина немонотонности (1 - обычный монотонный поиск Армихо).
+// Возвращает найденную точку минимума, количество итераций и количество дроблений шага.
+func barzilaiBorwein(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, variant bbVariant, memory int) ([]float64, int, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter, backtracks := 0, 0
+	grad := obj.Gradient(x)
+	history := []float64{obj.Value(x)} // Последние memory значений f
+	alpha := 1 / math.Max(common_funcs.VectorNorm(grad), 1)
+
+	// Основной цикл метода
+	for iter < maxIter {
+		gradNormSq := common_funcs.DotProduct(grad, grad)
+
+		// Критерий остановки
+		if math.Sqrt(gradNormSq) < epsilon {
+			break
+		}
+
+		// 1. Немонотонный поиск: опорное значение - максимум последних f
+		fRef := history[0]
+		for _, f := range history {
+			fRef = math.Max(fRef, f)
+		}
+		var xNext []float64
+		var fNext float64
+		stalled := false
+		for {
+			xNext = common_funcs.VectorAdd(x, common_funcs.ScalarMult(-alpha, grad))
+			fNext = obj.Value(xNext)
+			if fNext <= fRef-1e-4*alpha*gradNormSq {
+				break
+			}
+			if alpha < bbMinStep {
+				stalled = true
+				break
+			}
+			alpha /= 2
+			backtracks++
+		}
+		if stalled {
+			fmt.Printf("Метод Барзилая-Борвейна (%s): не удалось подобрать шаг, уменьшающий функцию.\n", variant)
+			break
+		}
+
+		// 2. Новый шаг Барзилая-Борвейна
+		gradNext := obj.Gradient(xNext)
+		s := common_funcs.VectorSub(xNext, x)
+		y := common_funcs.VectorSub(gradNext, grad)
+		sy := common_funcs.DotProduct(s, y)
+		if sy <= 0 {
+			alpha = bbMaxStep
+		} else if variant == BB2 {
+			alpha = sy / common_funcs.DotProduct(y, y)
+		} else {
+			alpha = common_funcs.DotProduct(s, s) / sy
+		}
+		alpha = math.Min(math.Max(alpha, bbMinStep), bbMaxStep)
+
+		history = append(history, fNext)
+		if len(history) > memory {
+			history = history[1:]
+		}
+		x, grad = xNext, gradNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Printf("Метод Барзилая-Борвейна (%s) достиг максимального числа итераций.\n", variant)
+	}
+	return x, iter, backtracks // Возвращаем результат
+}
+
+// printResult выводит результаты одного метода в общем для проекта формате.
+func printResult(title string, obj common_funcs.Objective, minX []float64, iterations int, counted *common_funcs.CountingObjective) {
+	fmt.Printf("\n%s:\n", title)
+	fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", obj.Value(minX))
+	fmt.Printf("Количество итераций: %d\n", iterations)
+	fmt.Println("Стоимость:", counted)
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden", "одномерный поиск для наискорейшего спуска через запятую: "+common_funcs.LineSearchUsage)
+	momentum := flag.Float64("momentum", 0.9, "коэффициент инерции метода тяжелого шарика")
+	bbMemory := flag.Int("bb-memory", 10, "глубина немонотонного поиска метода Барзилая-Борвейна")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if *momentum < 0 || *momentum >= 1 {
+		fmt.Println("Коэффициент инерции должен лежать в [0, 1):", *momentum)
+		os.Exit(2)
+	}
+	if *bbMemory < 1 {
+		fmt.Println("Глубина немонотонного поиска должна быть положительной:", *bbMemory)
+		os.Exit(2)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 10000                               // Макс. итераций (методы первого порядка сходятся медленно)
+
+	for _, lineSearch := range lineSearches {
+		counted := common_funcs.NewCountingObjective(obj)
+		minX, iterations := steepestDescent(counted, startPoint, epsilon, maxIter, lineSearch)
+		printResult(fmt.Sprintf("Метод наискорейшего спуска (%s)", lineSearch), obj, minX, iterations, counted)
+	}
+
+	counted := common_funcs.NewCountingObjective(obj)
+	minX, iterations, restarts := heavyBall(counted, startPoint, epsilon, maxIter, *momentum)
+	printResult(fmt.Sprintf("Метод тяжелого шарика (инерция %g)", *momentum), obj, minX, iterations, counted)
+	fmt.Println("Рестарты инерции:", restarts)
+
+	counted = common_funcs.NewCountingObjective(obj)
+	minX, iterations, restarts = nesterov(counted, startPoint, epsilon, maxIter)
+	printResult("Ускоренный метод Нестерова", obj, minX, iterations, counted)
+	fmt.Println("Рестарты инерции:", restarts)
+
+	for _, variant := range []bbVariant{BB1, BB2} {
+		counted = common_funcs.NewCountingObjective(obj)
+		minX, iterations, backtracks := barzilaiBorwein(counted, startPoint, epsilon, maxIter, variant, *bbMemory)
+		printResult(fmt.Sprintf("Метод Барзилая-Борвейна (%s, немонотонный поиск глубины %d)", variant, *bbMemory), obj, minX, iterations, counted)
+		fmt.Println("Дроблений шага:", backtracks)
+	}
+}
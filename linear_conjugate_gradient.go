@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/preconditioner"
+	"os"
+	"strings"
+)
+
+// laplacian возвращает матрично-свободный оператор одномерного разностного
+// Лапласиана A = tridiag(-1, 2, -1) размерности n: A·v вычисляется за O(n)
+// без хранения матрицы.
+func laplacian(n int) common_funcs.LinearOperator {
+	return func(v []float64) []float64 {
+		av := make([]float64, n)
+		for i := range v {
+			av[i] = 2 * v[i]
+			if i > 0 {
+				av[i] -= v[i-1]
+			}
+			if i < n-1 {
+				av[i] -= v[i+1]
+			}
+		}
+		return av
+	}
+}
+
+// quadraticObjective собирает целевую функцию ½xᵀAx - bᵀx по оператору A.
+// Явная матрица строится только по запросу Гессиана (для предобусловливателей).
+func quadraticObjective(a common_funcs.LinearOperator, b []float64) common_funcs.HessianObjective {
+	n := len(b)
+	return common_funcs.NewHessianObjective(n,
+		func(x []float64) float64 {
+			return 0.5*common_funcs.DotProduct(x, a(x)) - common_funcs.DotProduct(b, x)
+		},
+		func(x []float64) []float64 {
+			return common_funcs.VectorSub(a(x), b)
+		},
+		func(x []float64) common_funcs.Matrix {
+			m := common_funcs.NewMatrix(n, n)
+			for j := 0; j < n; j++ {
+				e := make([]float64, n)
+				e[j] = 1
+				for i, v := range a(e) {
+					m[i][j] = v
+				}
+			}
+			return m
+		})
+}
+
+// newPreconditioner создает предобусловливатель по имени для матрицы A = ∇²f.
+// Матрица постоянна, поэтому предобусловливатель строится один раз в точке x0.
+func newPreconditioner(name string, obj common_funcs.HessianObjective, x0 []float64) (preconditioner.Preconditioner, bool) {
+	var p preconditioner.Preconditioner
+	switch name {
+	case "none":
+		p = preconditioner.Identity{}
+	case "diag":
+		p = preconditioner.NewDiagonal(obj, 1)
+	case "ichol":
+		p = preconditioner.NewIncompleteCholesky(obj, 1)
+	default:
+		return nil, false
+	}
+	p.Update(x0, obj.Gradient(x0))
+	return p, true
+}
+
+// formatResiduals выводит нормы невязок в экспоненциальной записи.
+func formatResiduals(r []float64) string {
+	parts := make([]string, len(r))
+	for i, v := range r {
+		parts[i] = fmt.Sprintf("%.2e", v)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func main() {
+	formula := flag.String("f", "", "квадратичная целевая функция в виде формулы, например \"x1^2 + x1*x2 + 3*x2^2 - x1\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	laplaceDim := flag.Int("laplace", 8, "размерность системы с матрично-свободным Лапласианом tridiag(-1, 2, -1), если формула не задана")
+	precondList := flag.String("precond", "none,diag,ichol", "предобусловливатели через запятую: none, diag, ichol")
+	flag.Parse()
+
+	// Задача: квадратичная функция из формулы (A и b определяются автоматически)
+	// или система с Лапласианом и правой частью из единиц
+	var operator common_funcs.LinearOperator
+	var b []float64
+	var obj common_funcs.HessianObjective
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		a, rhs, ok := common_funcs.QuadraticForm(parsed)
+		if !ok {
+			fmt.Println("Целевая функция не является квадратичной; используйте нелинейный метод сопряженных градиентов (conjugate_gradient.go)")
+			os.Exit(1)
+		}
+		if _, ok := a.Cholesky(); !ok {
+			// Иначе невязка может обнулиться в седловой точке, которая была бы выдана за минимум
+			fmt.Println("Матрица A = ∇²f не является положительно определенной: у квадратичной функции нет минимума")
+			os.Exit(1)
+		}
+		operator, b, obj = a.Operator(), rhs, parsed
+	} else {
+		if *laplaceDim < 1 {
+			fmt.Println("Размерность системы должна быть положительной:", *laplaceDim)
+			os.Exit(2)
+		}
+		b = make([]float64, *laplaceDim)
+		for i := range b {
+			b[i] = 1
+		}
+		operator = laplacian(*laplaceDim)
+		obj = quadraticObjective(operator, b)
+	}
+
+	n := len(b)
+	startPoint := make([]float64, n) // Начальная точка (нулевая)
+	epsilon := 1e-10                 // Точность (норма невязки)
+	maxIter := 10 * n                // Макс. итераций (в точной арифметике хватает n)
+
+	for _, name := range strings.Split(*precondList, ",") {
+		name = strings.TrimSpace(name)
+		precond, ok := newPreconditioner(name, obj, startPoint)
+		if !ok {
+			fmt.Println("Неизвестный предобусловливатель:", name)
+			os.Exit(2)
+		}
+
+		// Вызываем метод
+		res := common_funcs.LinearCG(operator, b, startPoint, epsilon, maxIter, precond.Apply)
+
+		// Выводим результаты
+		fmt.Printf("\nЛинейный метод сопряженных градиентов (%s):\n", precond)
+		if n <= 10 {
+			fmt.Println("Найденный минимум x:", common_funcs.FormatVector(res.X))
+		}
+		fmt.Printf("Значение функции в минимуме f(x): %.6f\n", obj.Value(res.X))
+		fmt.Printf("Количество итераций: %d (размерность n = %d)\n", res.Iter, n)
+		fmt.Println("Нормы невязок:", formatResiduals(res.Residuals))
+		switch {
+		case res.Curvature:
+			fmt.Println("Матрица A не является положительно определенной: pᵀAp ≤ 0")
+		case !res.Converged:
+			fmt.Println("Линейный метод сопряженных градиентов достиг максимального числа итераций.")
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"optimizationMethodsTask4/autodiff"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/trust_region"
+	"os"
+)
+
+// newtonCGTrustRegion реализует метод Ньютона-CG с доверительной областью
+// (усеченные сопряженные градиенты Штайхауга-Тоинта). Гессиан используется только
+// через произведения на вектор, поэтому метод применим к задачам, для которых
+// полную матрицу Гессе нельзя ни построить, ни разложить.
+// obj - целевая функция (должна уметь умножать Гессиан на вектор).
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций (пробных шагов).
+// initialRadius - начальный радиус доверительной области.
+// maxRadius - наибольший допустимый радиус.
+// Возвращает найденную точку минимума, количество итераций, общее число
+// произведений Гессиана на вектор и количество остановок внутреннего цикла по причинам.
+func newtonCGTrustRegion(obj common_funcs.HessianVectorObjective, startPoint []float64, epsilon float64, maxIter int, initialRadius, maxRadius float64) ([]float64, int, int, map[trust_region.CGStop]int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	radius := initialRadius
+	products := 0                          // Всего произведений Гессиана на вектор
+	stops := map[trust_region.CGStop]int{} // Причины остановки внутреннего цикла
+
+	f := obj.Value(x)
+	grad := obj.Gradient(x)
+	hv := func(d []float64) []float64 { return obj.HessianVector(x, d) }
+
+	// Основной цикл метода
+	for iter < maxIter {
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			break
+		}
+
+		// 1. Приближенно решаем подзадачу усеченными сопряженными градиентами
+		step, stop, n := trust_region.SteihaugCG(grad, hv, radius, trust_region.ForcingTolerance(grad), 2*len(x))
+		products += n + 1
+		stops[stop]++
+		stepNorm := common_funcs.VectorNorm(step)
+
+		// 2. Сравниваем предсказанное моделью и фактическое уменьшение функции
+		pred := trust_region.ModelReduction(grad, step, hv(step))
+		xNext := common_funcs.VectorAdd(x, step)
+		fNext := obj.Value(xNext)
+		_, newRadius, accept := trust_region.UpdateRadius(f-fNext, pred, radius, stepNorm, maxRadius)
+
+		// 3. Принимаем шаг, только если модель достаточно хорошо предсказала уменьшение
+		if accept {
+			x = xNext
+			f = fNext
+			grad = obj.Gradient(x)
+		}
+		radius = newRadius
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Метод Ньютона-CG с доверительной областью достиг максимального числа итераций.")
+	}
+	return x, iter, products, stops // Возвращаем результат
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	rosenbrock := flag.Int("rosenbrock", 0, "размерность расширенной функции Розенброка (четная, 0 - не использовать)")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164, формула из командной строки/файла
+	// или расширенная функция Розенброка большой размерности
+	var obj common_funcs.HessianVectorObjective = common_funcs.WithHessianVector(common_funcs.Task17164)
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	switch {
+	case parsed != nil:
+		obj = common_funcs.WithHessianVector(parsed)
+	case *rosenbrock > 0:
+		if *rosenbrock%2 != 0 {
+			fmt.Println("Размерность функции Розенброка должна быть четной:", *rosenbrock)
+			os.Exit(2)
+		}
+		obj = common_funcs.WithHessianVector(autodiff.NewReverseObjective(*rosenbrock, autodiff.ExtendedRosenbrock[autodiff.Var]))
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 500                                 // Макс. итераций
+	initialRadius := 1.0                           // Начальный радиус доверительной области
+	maxRadius := 100.0                             // Максимальный радиус
+
+	// Вызываем метод
+	minX, iterations, products, stops := newtonCGTrustRegion(obj, startPoint, epsilon, maxIter, initialRadius, maxRadius)
+	minF := obj.Value(minX) // Значение функции в минимуме
+
+	// Выводим результаты
+	fmt.Println("\nМетод Ньютона-CG с доверительной областью (Штайхауг-Тоинт):")
+	if len(minX) <= 10 {
+		fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+	}
+	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+	fmt.Printf("Количество итераций: %d\n", iterations)
+	fmt.Printf("Произведений Гессиана на вектор: %d\n", products)
+	for _, s := range []trust_region.CGStop{trust_region.CGConverged, trust_region.CGNegativeCurvature, trust_region.CGBoundary, trust_region.CGMaxIter} {
+		fmt.Printf("Остановок внутреннего цикла (%s): %d\n", s, stops[s])
+	}
+}
@@ -1,30 +1,42 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"os"
 )
 
+// maxHessianCond - порог оценки числа обусловленности, выше которого
+// Гессиан считается численно вырожденным: при κ ≈ 1e12 в шаге Ньютона
+// остается лишь около четырех верных знаков.
+const maxHessianCond = 1e12
+
 // newtonMethod реализует модифицированный метод Ньютона с одномерным поиском шага.
+// obj - целевая функция (должна уметь вычислять Гессиан).
 // startPoint - начальная точка.
 // epsilon - точность (норма градиента).
 // maxIter - максимальное количество итераций.
-// lineSearchMaxAlpha - верхняя граница для поиска шага alpha.
-// lineSearchTol - точность для метода золотого сечения.
-// Возвращает найденную точку минимума и количество итераций.
-func newtonMethod(startPoint []float64, epsilon float64, maxIter int, lineSearchMaxAlpha, lineSearchTol float64) ([]float64, int) {
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// modification - способ модификации Гессиана, не являющегося положительно определенным
+// (NoModification - переход на антиградиент, как в исходной версии метода).
+// Возвращает найденную точку минимума, количество итераций и величину
+// модификации Гессиана (сдвиг) на каждой итерации.
+func newtonMethod(obj common_funcs.HessianObjective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, modification common_funcs.HessianModification) ([]float64, int, []float64) {
 	x := make([]float64, len(startPoint))
 	copy(x, startPoint)
 	iter := 0
 	dim := len(startPoint) // Размерность пространства
+	var shifts []float64   // Величина модификации Гессиана на каждой итерации
 
-	if dim != 3 {
-		panic("Метод Ньютона в данной реализации работает только для 3D")
+	if dim != obj.Dimension() {
+		panic(fmt.Sprintf("Размерность начальной точки (%d) не совпадает с размерностью функции (%d)", dim, obj.Dimension()))
 	}
 
 	// Основной цикл метода
 	for iter < maxIter {
-		grad := common_funcs.GradF(x)             // Градиент
+		grad := obj.Gradient(x)                   // Градиент
 		gradNorm := common_funcs.VectorNorm(grad) // Норма градиента
 
 		// Критерий остановки
@@ -32,24 +44,38 @@ func newtonMethod(startPoint []float64, epsilon float64, maxIter int, lineSearch
 			break
 		}
 
-		hess := common_funcs.Hessian(x) // Вычисляем Гессиан
-		// Пытаемся обратить Гессиан
-		hessInv, invertible := common_funcs.Inverse3x3(hess)
-		if !invertible {
-			fmt.Println("Гессиан не обратим на итерации", iter, ", остановка.")
+		hess := obj.Hessian(x) // Вычисляем Гессиан
+
+		if modification != common_funcs.NoModification {
+			// Делаем Гессиан положительно определенным, сохраняя информацию о кривизне:
+			// направление (H + E)^(-1) * (-grad) всегда является направлением спуска
+			solve, shift := common_funcs.ModifyHessian(hess, modification)
+			shifts = append(shifts, shift)
+			direction := solve(common_funcs.ScalarMult(-1.0, grad))
+			alpha := lineSearch.Step(obj, x, direction, grad)
+			x = common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
+			iter++
+			continue
+		}
+		shifts = append(shifts, 0)
+
+		// Решаем систему H * p = -grad через LU-разложение вместо обращения Гессиана
+		newtonStep, cond, solvable := common_funcs.SolveLinear(hess, common_funcs.ScalarMult(-1.0, grad))
+		if !solvable || cond > maxHessianCond {
+			fmt.Printf("Гессиан вырожден на итерации %d (оценка числа обусловленности %.3e), шаг по антиградиенту.\n", iter, cond)
 			// Можно попробовать перейти на шаг градиентного спуска в этом случае
 			direction := common_funcs.ScalarMult(-1.0, grad)
-			alpha := common_funcs.GoldenSectionSearch(x, direction, 0.0, lineSearchMaxAlpha, lineSearchTol)
+			alpha := lineSearch.Step(obj, x, direction, grad)
 			x = common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
 			iter++
 			continue // Продолжить со следующей итерации
 		}
 
-		// Вычисляем направление Ньютона: p_k = -H^(-1) * grad
-		direction := common_funcs.ScalarMult(-1.0, common_funcs.MatrixVectorMult(hessInv, grad))
+		// Направление Ньютона: p_k = -H^(-1) * grad
+		direction := newtonStep
 
 		// Проверка направления спуска (должно быть < 0)
-		// Если hessInv положительно определена, это условие выполнится
+		// Если Гессиан положительно определен, это условие выполнится
 		dotProd := common_funcs.DotProduct(grad, direction)
 		if dotProd >= 0 {
 			fmt.Println("Направление Ньютона не является направлением спуска на итерации", iter, ", переключение на антиградиент.")
@@ -58,8 +84,8 @@ func newtonMethod(startPoint []float64, epsilon float64, maxIter int, lineSearch
 			direction = common_funcs.ScalarMult(-1.0, grad)
 		}
 
-		// Ищем оптимальный шаг alpha с помощью золотого сечения вдоль направления direction
-		alpha := common_funcs.GoldenSectionSearch(x, direction, 0.0, lineSearchMaxAlpha, lineSearchTol)
+		// Ищем шаг alpha с помощью одномерного поиска вдоль направления direction
+		alpha := lineSearch.Step(obj, x, direction, grad)
 
 		// Обновляем текущую точку: x = x + alpha * direction
 		x = common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
@@ -71,23 +97,59 @@ func newtonMethod(startPoint []float64, epsilon float64, maxIter int, lineSearch
 	if iter == maxIter {
 		fmt.Println("Метод Ньютона достиг максимального числа итераций.")
 	}
-	return x, iter // Возвращаем результат
+	return x, iter, shifts // Возвращаем результат
 }
 
 func main() {
-	startPoint := []float64{0.0, 0.0, 0.0} // Начальная точка 3D
-	epsilon := 1e-5                        // Точность
-	maxIter := 100                         // Макс. итераций (Ньютон обычно сходится быстро)
-	lineSearchMaxAlpha := 1.0              // Макс. alpha для GSS
-	lineSearchTol := 1e-6                  // Точность для GSS
-
-	// Вызываем метод
-	minX, iterations := newtonMethod(startPoint, epsilon, maxIter, lineSearchMaxAlpha, lineSearchTol)
-	minF := common_funcs.F(minX) // Значение функции в минимуме
-
-	// Выводим результаты
-	fmt.Println("\nМетод Ньютона (модифицированный):")
-	fmt.Printf("Найденный минимум x: [%.6f, %.6f, %.6f]\n", minX[0], minX[1], minX[2])
-	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
-	fmt.Printf("Количество итераций: %d\n", iterations)
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden,armijo", "одномерный поиск через запятую: "+common_funcs.LineSearchUsage)
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 100                                 // Макс. итераций (Ньютон обычно сходится быстро)
+
+	modifications := []common_funcs.HessianModification{
+		common_funcs.NoModification,
+		common_funcs.LevenbergShift,
+		common_funcs.GillMurrayCholesky,
+		common_funcs.EigenvalueFlip,
+	}
+	baseline := common_funcs.CostBaseline{}
+	for _, lineSearch := range lineSearches {
+		for _, modification := range modifications {
+			// Вызываем метод, считая вычисления функции и производных
+			counted := common_funcs.NewCountingObjective(obj)
+			minX, iterations, shifts := newtonMethod(counted, startPoint, epsilon, maxIter, lineSearch, modification)
+			minF := obj.Value(minX) // Значение функции в минимуме
+
+			// Выводим результаты
+			fmt.Printf("\nМетод Ньютона (модифицированный, %s, %s):\n", modification, lineSearch)
+			fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+			fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+			fmt.Printf("Количество итераций: %d\n", iterations)
+			fmt.Printf("Модификация Гессиана по итерациям: %.3e\n", shifts)
+			fmt.Println("Стоимость:", counted)
+			if saving := baseline.Compare(modification.String(), lineSearch, counted); saving != "" {
+				fmt.Println("По сравнению с золотым сечением:", saving)
+			}
+		}
+	}
 }
@@ -0,0 +1,341 @@
+// Package one_dim содержит методы одномерной минимизации унимодальной функции
+// f: R → R на отрезке [a, b]: золотое сечение, метод Фибоначчи, дихотомию,
+// последовательную параболическую интерполяцию и метод Брента, а также
+// локализацию отрезка с минимумом методом Свенна. Пакет не зависит от
+// многомерных функций: одномерный поиск шага строит f(α) = F(x + α·d) сам.
+package one_dim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Result - найденная точка минимума, значение в ней и количество вычислений f.
+// X - лучшая из точек, в которых вычислялась f, поэтому F не требует
+// дополнительного вычисления.
+type Result struct {
+	X, F  float64
+	Evals int
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("x = %.8f, f(x) = %.8f, вычислений f: %d", r.X, r.F, r.Evals)
+}
+
+// Method - метод одномерной минимизации с именем для вывода.
+// Minimize ищет минимум f на [a, b] с точностью tol по x.
+type Method struct {
+	Name     string
+	Minimize func(f func(float64) float64, a, b, tol float64) Result
+}
+
+var (
+	Golden    = Method{Name: "золотое сечение", Minimize: GoldenSection}
+	Fibonacci = Method{Name: "метод Фибоначчи", Minimize: FibonacciSearch}
+	Dichotomy = Method{Name: "дихотомия", Minimize: DichotomySearch}
+	Parabolic = Method{Name: "метод парабол", Minimize: ParabolicInterpolation}
+	BrentM    = Method{Name: "метод Брента", Minimize: Brent}
+)
+
+// methods - методы по именам для флагов командной строки.
+var methods = map[string]Method{
+	"golden":    Golden,
+	"fibonacci": Fibonacci,
+	"dichotomy": Dichotomy,
+	"parabolic": Parabolic,
+	"brent":     BrentM,
+}
+
+// MethodByName возвращает метод по имени: golden, fibonacci, dichotomy, parabolic, brent.
+func MethodByName(name string) (Method, bool) {
+	m, ok := methods[name]
+	return m, ok
+}
+
+// MethodNames возвращает допустимые имена методов в алфавитном порядке.
+func MethodNames() []string {
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// counter оборачивает f подсчетом вычислений.
+func counter(f func(float64) float64) (func(float64) float64, *int) {
+	evals := new(int)
+	return func(x float64) float64 {
+		*evals++
+		return f(x)
+	}, evals
+}
+
+// GoldenSection - метод золотого сечения: отрезок на каждом шаге сокращается
+// в φ ≈ 1.618 раз, одна из внутренних точек переиспользуется.
+func GoldenSection(f func(float64) float64, a, b, tol float64) Result {
+	f, evals := counter(f)
+	phi := (1 + math.Sqrt(5)) / 2
+	resPhi := 2 - phi
+	x1 := a + resPhi*(b-a)
+	x2 := b - resPhi*(b-a)
+	f1 := f(x1)
+	f2 := f(x2)
+
+	for math.Abs(b-a) > tol {
+		if f1 < f2 {
+			b = x2
+			x2 = x1
+			f2 = f1
+			x1 = a + resPhi*(b-a)
+			f1 = f(x1)
+		} else {
+			a = x1
+			x1 = x2
+			f1 = f2
+			x2 = b - resPhi*(b-a)
+			f2 = f(x2)
+		}
+	}
+	return best(x1, f1, x2, f2, *evals)
+}
+
+// best возвращает лучшую из двух точек.
+func best(x1, f1, x2, f2 float64, evals int) Result {
+	if f1 < f2 {
+		return Result{X: x1, F: f1, Evals: evals}
+	}
+	return Result{X: x2, F: f2, Evals: evals}
+}
+
+// FibonacciSearch - метод Фибоначчи: число вычислений n выбирается заранее
+// (F_n ≥ 2(b-a)/tol), и внутренние точки делят отрезок в отношениях F_{k-2}/F_k и F_{k-1}/F_k.
+// Среди методов с фиксированным числом вычислений дает наименьший итоговый отрезок.
+func FibonacciSearch(f func(float64) float64, a, b, tol float64) Result {
+	f, evals := counter(f)
+	fib := []float64{1, 1, 2, 3}
+	for fib[len(fib)-1] < 2*(b-a)/tol {
+		fib = append(fib, fib[len(fib)-1]+fib[len(fib)-2])
+	}
+	m := len(fib) - 1
+	x1 := a + fib[m-2]/fib[m]*(b-a)
+	x2 := a + fib[m-1]/fib[m]*(b-a)
+	f1 := f(x1)
+	f2 := f(x2)
+
+	for m > 3 {
+		m--
+		if f1 < f2 {
+			b = x2
+			x2 = x1
+			f2 = f1
+			x1 = a + fib[m-2]/fib[m]*(b-a)
+			f1 = f(x1)
+		} else {
+			a = x1
+			x1 = x2
+			f1 = f2
+			x2 = a + fib[m-1]/fib[m]*(b-a)
+			f2 = f(x2)
+		}
+	}
+	return best(x1, f1, x2, f2, *evals)
+}
+
+// DichotomySearch - метод дихотомии: функция вычисляется в двух точках,
+// отстоящих на δ = tol/4 от середины отрезка, и отрезок сокращается почти вдвое.
+// Требует двух вычислений на итерацию (золотое сечение - одного).
+func DichotomySearch(f func(float64) float64, a, b, tol float64) Result {
+	f, evals := counter(f)
+	delta := tol / 4
+	res := Result{X: (a + b) / 2, F: math.Inf(1)}
+	for b-a > tol {
+		mid := (a + b) / 2
+		x1, x2 := mid-delta, mid+delta
+		f1, f2 := f(x1), f(x2)
+		if f1 < f2 {
+			b = x2
+		} else {
+			a = x1
+		}
+		res = best(x1, f1, x2, f2, 0)
+	}
+	if math.IsInf(res.F, 1) {
+		res.F = f(res.X) // Отрезок с самого начала короче tol
+	}
+	res.Evals = *evals
+	return res
+}
+
+// ParabolicInterpolation - метод последовательной параболической интерполяции
+// (метод парабол): через три точки x1 < x2 < x3 проводится парабола, ее вершина
+// заменяет одну из точек. Если вершина не определена, выходит за отрезок или
+// параболические шаги перестали сокращать отрезок, берется точка золотого
+// сечения большей части отрезка. Остановка - когда
+// отрезок меньше tol или вершина сдвинулась меньше чем на tol/2.
+func ParabolicInterpolation(f func(float64) float64, a, b, tol float64) Result {
+	f, evals := counter(f)
+	resPhi := (3 - math.Sqrt(5)) / 2
+	x1, x2, x3 := a, (a+b)/2, b
+	f1, f2, f3 := f(x1), f(x2), f(x3)
+	prev := math.Inf(1)
+	widths := [2]float64{math.Inf(1), math.Inf(1)} // Длины отрезка на двух прошлых итерациях
+
+	for i := 0; x3-x1 > tol && i < 500; i++ {
+		num := (x2-x1)*(x2-x1)*(f2-f3) - (x2-x3)*(x2-x3)*(f2-f1)
+		den := (x2-x1)*(f2-f3) - (x2-x3)*(f2-f1)
+		u := math.NaN()
+		// Если за две итерации отрезок не сократился вдвое (один конец "застрял"),
+		// делаем шаг золотого сечения вместо параболы
+		if den != 0 && x3-x1 <= 0.5*widths[0] {
+			u = x2 - 0.5*num/den
+		}
+		widths[0], widths[1] = widths[1], x3-x1
+		if math.IsNaN(u) || u <= x1 || u >= x3 {
+			// Шаг золотого сечения в большую из частей
+			if x2-x1 > x3-x2 {
+				u = x2 - resPhi*(x2-x1)
+			} else {
+				u = x2 + resPhi*(x3-x2)
+			}
+		}
+		// Точки не должны совпадать, иначе парабола вырождается
+		if math.Abs(u-x2) < tol/4 {
+			if x2-x1 > x3-x2 {
+				u = x2 - tol/4
+			} else {
+				u = x2 + tol/4
+			}
+		}
+		fu := f(u)
+		if u < x2 {
+			if fu < f2 {
+				x3, f3 = x2, f2
+				x2, f2 = u, fu
+			} else {
+				x1, f1 = u, fu
+			}
+		} else {
+			if fu < f2 {
+				x1, f1 = x2, f2
+				x2, f2 = u, fu
+			} else {
+				x3, f3 = u, fu
+			}
+		}
+		if math.Abs(u-prev) < tol/2 {
+			break
+		}
+		prev = u
+	}
+	return Result{X: x2, F: f2, Evals: *evals}
+}
+
+// Brent - метод Брента: параболическая интерполяция по трем лучшим точкам,
+// подстрахованная шагами золотого сечения, когда парабола дает плохой шаг.
+// Сходится сверхлинейно для гладких функций и не медленнее золотого сечения
+// в худшем случае (Брент, 1973; алгоритм fmin).
+func Brent(f func(float64) float64, a, b, tol float64) Result {
+	f, evals := counter(f)
+	const resPhi = 0.3819660112501051 // (3 - √5) / 2
+	eps := math.Sqrt(2.2e-16)
+
+	x := a + resPhi*(b-a)
+	w, v := x, x
+	fx := f(x)
+	fw, fv := fx, fx
+	d, e := 0.0, 0.0 // Последний и предпоследний шаги
+
+	for {
+		m := (a + b) / 2
+		tol1 := eps*math.Abs(x) + tol/3
+		tol2 := 2 * tol1
+		if math.Abs(x-m) <= tol2-(b-a)/2 {
+			break
+		}
+		golden := true
+		if math.Abs(e) > tol1 {
+			// Пробуем параболу через x, w, v
+			r := (x - w) * (fx - fv)
+			q := (x - v) * (fx - fw)
+			p := (x-v)*q - (x-w)*r
+			q = 2 * (q - r)
+			if q > 0 {
+				p = -p
+			} else {
+				q = -q
+			}
+			if math.Abs(p) < math.Abs(0.5*q*e) && p > q*(a-x) && p < q*(b-x) {
+				e = d
+				d = p / q
+				u := x + d
+				if u-a < tol2 || b-u < tol2 {
+					d = math.Copysign(tol1, m-x)
+				}
+				golden = false
+			}
+		}
+		if golden {
+			if x < m {
+				e = b - x
+			} else {
+				e = a - x
+			}
+			d = resPhi * e
+		}
+		u := x + d
+		if math.Abs(d) < tol1 {
+			u = x + math.Copysign(tol1, d)
+		}
+		fu := f(u)
+		if fu <= fx {
+			if u < x {
+				b = x
+			} else {
+				a = x
+			}
+			v, fv = w, fw
+			w, fw = x, fx
+			x, fx = u, fu
+		} else {
+			if u < x {
+				a = u
+			} else {
+				b = u
+			}
+			if fu <= fw || w == x {
+				v, fv = w, fw
+				w, fw = u, fu
+			} else if fu <= fv || v == x || v == w {
+				v, fv = u, fu
+			}
+		}
+	}
+	return Result{X: x, F: fx, Evals: *evals}
+}
+
+// Swann находит отрезок [a, b] ⊂ [0, ∞), содержащий минимум f, методом Свенна:
+// шаг h удваивается (0, h, 3h, 7h, ...), пока значение функции убывает.
+// Три последние точки α₋ < α < α₊ с f(α) ≤ f(α₋), f(α) < f(α₊) дают отрезок
+// [α₋, α₊]. Если f(h) ≥ f(0), минимум лежит на [0, h]. После maxSteps удвоений
+// (функция неограниченно убывает) возвращается последний пройденный отрезок.
+func Swann(f func(float64) float64, h float64, maxSteps int) (a, b float64, evals int) {
+	f, count := counter(f)
+	f0 := f(0)
+	fCur := f(h)
+	if !(fCur < f0) {
+		return 0, h, *count
+	}
+	prev, cur, step := 0.0, h, h
+	for i := 0; i < maxSteps; i++ {
+		step *= 2
+		next := cur + step
+		fNext := f(next)
+		if !(fNext < fCur) {
+			return prev, next, *count
+		}
+		prev, cur, fCur = cur, next, fNext
+	}
+	return prev, cur, *count
+}
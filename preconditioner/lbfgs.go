@@ -0,0 +1,90 @@
+package preconditioner
+
+import (
+	"fmt"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// LBFGSMemory хранит m последних пар (s, y) = (delta, gamma) метода L-BFGS
+// в кольцевом буфере. Память O(m·n) вместо O(n²) для матрицы H.
+type LBFGSMemory struct {
+	s, y        [][]float64 // Пары векторов, старейшая - в позиции start
+	rho         []float64   // ρᵢ = 1 / sᵢᵀyᵢ
+	start, size int
+}
+
+// NewLBFGSMemory создает пустую память на m пар.
+func NewLBFGSMemory(m int) *LBFGSMemory {
+	if m < 1 {
+		panic(fmt.Sprintf("Память L-BFGS должна быть положительной, получено: %d", m))
+	}
+	return &LBFGSMemory{s: make([][]float64, m), y: make([][]float64, m), rho: make([]float64, m)}
+}
+
+// Push добавляет новую пару, вытесняя самую старую при заполненной памяти.
+// Пара отбрасывается (возвращается false), если нарушено условие кривизны
+// sᵀy > 1e-10·‖s‖‖y‖ - иначе H перестала бы быть положительно определенной.
+func (mem *LBFGSMemory) Push(s, y []float64) bool {
+	sy := common_funcs.DotProduct(s, y)
+	if sy <= 1e-10*common_funcs.VectorNorm(s)*common_funcs.VectorNorm(y) {
+		return false
+	}
+	m := len(mem.s)
+	i := (mem.start + mem.size) % m
+	if mem.size == m {
+		mem.start = (mem.start + 1) % m
+	} else {
+		mem.size++
+	}
+	mem.s[i], mem.y[i], mem.rho[i] = s, y, 1/sy
+	return true
+}
+
+// at возвращает индекс k-й по возрасту пары (0 - самая старая).
+func (mem *LBFGSMemory) at(k int) int {
+	return (mem.start + k) % len(mem.s)
+}
+
+// Apply вычисляет H·v двухцикловой рекурсией, не строя H.
+// Начальная матрица H0 = γI, γ = sᵀy / yᵀy для последней пары (1 при пустой памяти).
+func (mem *LBFGSMemory) Apply(v []float64) []float64 {
+	q := make([]float64, len(v))
+	copy(q, v)
+	alpha := make([]float64, mem.size)
+
+	// Первый цикл: от новых пар к старым
+	for k := mem.size - 1; k >= 0; k-- {
+		i := mem.at(k)
+		alpha[k] = mem.rho[i] * common_funcs.DotProduct(mem.s[i], q)
+		axpy(-alpha[k], mem.y[i], q)
+	}
+
+	gamma := 1.0
+	if mem.size > 0 {
+		i := mem.at(mem.size - 1)
+		gamma = 1 / (mem.rho[i] * common_funcs.DotProduct(mem.y[i], mem.y[i]))
+	}
+	for j := range q {
+		q[j] *= gamma
+	}
+
+	// Второй цикл: от старых пар к новым
+	for k := 0; k < mem.size; k++ {
+		i := mem.at(k)
+		beta := mem.rho[i] * common_funcs.DotProduct(mem.y[i], q)
+		axpy(alpha[k]-beta, mem.s[i], q)
+	}
+	return q
+}
+
+// Reset очищает память (H снова равна единичной матрице).
+func (mem *LBFGSMemory) Reset() {
+	mem.start, mem.size = 0, 0
+}
+
+// axpy выполняет y += a*x на месте, без выделения памяти.
+func axpy(a float64, x, y []float64) {
+	for i := range y {
+		y[i] += a * x[i]
+	}
+}
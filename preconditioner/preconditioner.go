@@ -0,0 +1,202 @@
+// Package preconditioner содержит предобусловливатели для метода сопряженных
+// градиентов: матрицу M ≈ ∇²f, для которой легко вычислить z = M⁻¹v. Направление
+// и коэффициент beta строятся по предобусловленному градиенту z = M⁻¹g, и на
+// плохо масштабированных функциях метод ведет себя так, как если бы задача
+// была масштабирована хорошо.
+package preconditioner
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// Preconditioner - предобусловливатель M.
+type Preconditioner interface {
+	Update(x, grad []float64)    // Сообщает новую точку и градиент в ней (M может измениться)
+	Apply(v []float64) []float64 // Вычисляет M⁻¹v
+	String() string
+}
+
+// Identity - отсутствие предобусловливания (M = I).
+type Identity struct{}
+
+func (Identity) Update(x, grad []float64) {}
+
+func (Identity) Apply(v []float64) []float64 {
+	return append([]float64(nil), v...)
+}
+
+func (Identity) String() string {
+	return "без предобусловливания"
+}
+
+// funcPreconditioner - предобусловливатель, заданный пользователем функцией M⁻¹v.
+type funcPreconditioner struct {
+	name  string
+	apply func([]float64) []float64
+}
+
+func (p funcPreconditioner) Update(x, grad []float64) {}
+
+func (p funcPreconditioner) Apply(v []float64) []float64 {
+	return p.apply(v)
+}
+
+func (p funcPreconditioner) String() string {
+	return p.name
+}
+
+// FromFunc создает постоянный предобусловливатель из функции apply(v) = M⁻¹v.
+func FromFunc(name string, apply func([]float64) []float64) Preconditioner {
+	return funcPreconditioner{name: name, apply: apply}
+}
+
+// FromMatrix создает постоянный предобусловливатель из симметричной положительно
+// определенной матрицы M. Возвращает false, если разложение Холецкого M невозможно.
+func FromMatrix(m common_funcs.Matrix) (Preconditioner, bool) {
+	c, ok := m.Cholesky()
+	if !ok {
+		return nil, false
+	}
+	return FromFunc("заданная матрица", c.Solve), true
+}
+
+// minDiagonal - относительная нижняя граница диагонали: M должна быть положительно
+// определенной даже там, где Гессиан вырожден или неопределен.
+const minDiagonal = 1e-3
+
+// maxShiftDoublings - предельное число удвоений сдвига τ в IncompleteCholesky.
+// Сдвиг β·2⁶⁰ заведомо делает конечный Гессиан диагонально преобладающим.
+const maxShiftDoublings = 60
+
+// Diagonal - диагональный предобусловливатель M = diag(|∇²f|ᵢᵢ) (метод Якоби).
+// Гессиан пересчитывается каждые every вызовов Update. Если Гессиан содержит
+// NaN или ±Inf, до следующего пересчета M = I.
+type Diagonal struct {
+	obj   common_funcs.HessianObjective
+	every int
+	calls int
+	diag  []float64 // nil - без предобусловливания
+}
+
+// NewDiagonal создает диагональный предобусловливатель по Гессиану obj.
+func NewDiagonal(obj common_funcs.HessianObjective, every int) *Diagonal {
+	return &Diagonal{obj: obj, every: max(every, 1)}
+}
+
+func (p *Diagonal) Update(x, grad []float64) {
+	if p.calls%p.every == 0 {
+		hess := p.obj.Hessian(x)
+		if !hess.IsFinite() {
+			p.diag = nil
+			p.calls++
+			return
+		}
+		p.diag = make([]float64, len(x))
+		maxDiag := 0.0
+		for i := range p.diag {
+			p.diag[i] = math.Abs(hess[i][i])
+			maxDiag = math.Max(maxDiag, p.diag[i])
+		}
+		floor := minDiagonal * math.Max(maxDiag, 1)
+		for i := range p.diag {
+			p.diag[i] = math.Max(p.diag[i], floor)
+		}
+	}
+	p.calls++
+}
+
+func (p *Diagonal) Apply(v []float64) []float64 {
+	if p.diag == nil {
+		return Identity{}.Apply(v)
+	}
+	z := make([]float64, len(v))
+	for i := range v {
+		z[i] = v[i] / p.diag[i]
+	}
+	return z
+}
+
+func (p *Diagonal) String() string {
+	return "диагональ Гессиана"
+}
+
+// IncompleteCholesky - предобусловливатель M = L Lᵀ из неполного разложения
+// Холецкого IC(0) Гессиана. Если разложение не существует (Гессиан не
+// положительно определен), к диагонали добавляется сдвиг τI, удваиваемый до успеха.
+// Гессиан пересчитывается каждые every вызовов Update. Если Гессиан содержит
+// NaN или ±Inf либо сдвиг не помог за maxShiftDoublings удвоений, до следующего
+// пересчета M = I.
+type IncompleteCholesky struct {
+	obj   common_funcs.HessianObjective
+	every int
+	calls int
+	chol  *common_funcs.Cholesky // nil - без предобусловливания
+}
+
+// NewIncompleteCholesky создает предобусловливатель IC(0) по Гессиану obj.
+func NewIncompleteCholesky(obj common_funcs.HessianObjective, every int) *IncompleteCholesky {
+	return &IncompleteCholesky{obj: obj, every: max(every, 1)}
+}
+
+func (p *IncompleteCholesky) Update(x, grad []float64) {
+	if p.calls%p.every == 0 {
+		p.chol = nil
+		hess := p.obj.Hessian(x)
+		if hess.IsFinite() {
+			beta := minDiagonal * math.Max(hess.Norm1(), 1)
+			tau := 0.0
+			for k := 0; k <= maxShiftDoublings; k++ {
+				shifted := hess.Copy()
+				for i := range shifted {
+					shifted[i][i] += tau
+				}
+				if c, ok := shifted.IncompleteCholesky(); ok {
+					p.chol = c
+					break
+				}
+				tau = math.Max(2*tau, beta)
+			}
+		}
+	}
+	p.calls++
+}
+
+func (p *IncompleteCholesky) Apply(v []float64) []float64 {
+	if p.chol == nil {
+		return Identity{}.Apply(v)
+	}
+	return p.chol.Solve(v)
+}
+
+func (p *IncompleteCholesky) String() string {
+	return "неполное разложение Холецкого IC(0)"
+}
+
+// LBFGS - предобусловливатель, в котором M⁻¹ - матрица L-BFGS, построенная по
+// последним m парам (s, y) самого метода. Гессиан не нужен.
+type LBFGS struct {
+	mem             *LBFGSMemory
+	prevX, prevGrad []float64
+}
+
+// NewLBFGS создает L-BFGS-предобусловливатель с памятью на m пар.
+func NewLBFGS(m int) *LBFGS {
+	return &LBFGS{mem: NewLBFGSMemory(m)}
+}
+
+func (p *LBFGS) Update(x, grad []float64) {
+	if p.prevX != nil {
+		p.mem.Push(common_funcs.VectorSub(x, p.prevX), common_funcs.VectorSub(grad, p.prevGrad))
+	}
+	p.prevX = append([]float64(nil), x...)
+	p.prevGrad = append([]float64(nil), grad...)
+}
+
+func (p *LBFGS) Apply(v []float64) []float64 {
+	return p.mem.Apply(v)
+}
+
+func (p *LBFGS) String() string {
+	return "L-BFGS"
+}
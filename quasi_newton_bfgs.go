@@ -0,0 +1,211 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"os"
+)
+
+// bfgsForm - способ хранения квазиньютоновской матрицы в методе BFGS.
+type bfgsForm int
+
+const (
+	inverseForm bfgsForm = iota // Хранится H ≈ ∇²f⁻¹, направление d = -H·grad
+	factorForm                  // Хранится множитель Холецкого B = L Lᵀ ≈ ∇²f, направление из L Lᵀ d = -grad
+)
+
+func (f bfgsForm) String() string {
+	if f == inverseForm {
+		return "обратная матрица"
+	}
+	return "множитель Холецкого"
+}
+
+// Параметры защиты обновления BFGS
+const (
+	powellDamping  = 0.2   // Демпфирование Пауэлла при sᵀy < 0.2·sᵀBs
+	curvatureFloor = 1e-10 // Обновление пропускается при sᵀy ≤ curvatureFloor·‖s‖‖y‖
+)
+
+// quasiNewtonBFGS реализует Квазиньютоновский метод BFGS.
+// В отличие от поправки ранга 1 обновление BFGS сохраняет положительную
+// определенность матрицы, если выполнено условие кривизны sᵀy > 0. Оно
+// проверяется на каждой итерации; при damping = true вектор y заменяется
+// демпфированным по Пауэллу, иначе обновление при нарушении условия пропускается.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// resetInterval - интервал для сброса матрицы к единичной (0 - не сбрасывать).
+// form - хранимая матрица (обратная матрица H или множитель Холецкого B).
+// damping - использовать демпфирование Пауэлла.
+// Возвращает найденную точку минимума, количество итераций,
+// количество демпфированных и пропущенных обновлений.
+func quasiNewtonBFGS(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, resetInterval int, form bfgsForm, damping bool) ([]float64, int, int, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	dim := len(startPoint)
+	damped, skipped := 0, 0
+
+	// H - аппроксимация обратной матрицы Гессе (для inverseForm),
+	// chol - разложение B = L Lᵀ аппроксимации матрицы Гессе (для factorForm)
+	var H common_funcs.Matrix
+	var chol *common_funcs.Cholesky
+	scaled := false // Выполнено ли начальное масштабирование матрицы
+	reset := func() {
+		H = common_funcs.IdentityMatrix(dim)
+		chol, _ = common_funcs.IdentityMatrix(dim).Cholesky()
+		scaled = false
+	}
+	reset() // Начинаем с единичной матрицы
+
+	grad := obj.Gradient(x) // Начальный градиент
+
+	// Основной цикл метода
+	for iter < maxIter {
+		gradNorm := common_funcs.VectorNorm(grad) // Норма градиента
+
+		// Критерий остановки
+		if gradNorm < epsilon {
+			break
+		}
+
+		// Периодический сброс матрицы к единичной (для стабильности)
+		if resetInterval > 0 && iter%resetInterval == 0 && iter > 0 {
+			reset()
+		}
+
+		// 1. Вычисляем направление спуска: d = -H * grad или L Lᵀ d = -grad
+		var direction []float64
+		if form == inverseForm {
+			direction = common_funcs.ScalarMult(-1.0, common_funcs.MatrixVectorMult(H, grad))
+		} else {
+			direction = chol.Solve(common_funcs.ScalarMult(-1.0, grad))
+		}
+
+		// 2. Ищем шаг alpha с помощью одномерного поиска
+		alpha := lineSearch.Step(obj, x, direction, grad)
+
+		// 3. Обновляем точку: x_next = x + alpha * d
+		xNext := common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
+
+		// 4. Вычисляем новый градиент
+		gradNext := obj.Gradient(xNext)
+
+		// 5. Вычисляем векторы delta (s) и gamma (y) для обновления
+		delta := common_funcs.ScalarMult(alpha, direction) // delta = x_next - x
+		gamma := common_funcs.VectorSub(gradNext, grad)    // gamma = grad_next - grad
+		// Так как B·d = -grad, то B·delta = -alpha * grad без хранения B
+		bDelta := common_funcs.ScalarMult(-alpha, grad)
+		deltaBDelta := common_funcs.DotProduct(delta, bDelta)
+		deltaGamma := common_funcs.DotProduct(delta, gamma)
+
+		// 6. Проверяем условие кривизны sᵀy > 0 (при необходимости демпфируем y)
+		if damping && deltaGamma < powellDamping*deltaBDelta {
+			theta := (1 - powellDamping) * deltaBDelta / (deltaBDelta - deltaGamma)
+			gamma = common_funcs.VectorAdd(common_funcs.ScalarMult(theta, gamma), common_funcs.ScalarMult(1-theta, bDelta))
+			deltaGamma = common_funcs.DotProduct(delta, gamma)
+			damped++
+		}
+		if deltaGamma <= curvatureFloor*common_funcs.VectorNorm(delta)*common_funcs.VectorNorm(gamma) {
+			skipped++
+			x = xNext
+			grad = gradNext
+			iter++
+			continue
+		}
+
+		// Перед первым обновлением масштабируем единичную матрицу: H0 = (sᵀy / yᵀy) I
+		if !scaled {
+			scale := deltaGamma / common_funcs.DotProduct(gamma, gamma)
+			H = common_funcs.MatrixScalarMult(scale, H)
+			chol, _ = common_funcs.MatrixScalarMult(1/scale, common_funcs.IdentityMatrix(dim)).Cholesky()
+			bDelta = common_funcs.ScalarMult(1/scale, delta)
+			scaled = true
+		}
+
+		// 7. Обновляем матрицу по формуле BFGS
+		if form == inverseForm {
+			// H_next = (I - ρ s yᵀ) H (I - ρ y sᵀ) + ρ s sᵀ, ρ = 1 / sᵀy
+			Hgamma := common_funcs.MatrixVectorMult(H, gamma)
+			gammaHgamma := common_funcs.DotProduct(gamma, Hgamma)
+			rho := 1.0 / deltaGamma
+			H = common_funcs.MatrixAdd(H, common_funcs.MatrixScalarMult(-rho, common_funcs.MatrixAdd(
+				common_funcs.OuterProduct(delta, Hgamma), common_funcs.OuterProduct(Hgamma, delta))))
+			H = common_funcs.MatrixAdd(H, common_funcs.MatrixScalarMult(rho*rho*gammaHgamma+rho, common_funcs.OuterProduct(delta, delta)))
+		} else {
+			// B_next = B + y yᵀ / sᵀy - B s sᵀB / sᵀBs: сначала поправка, затем понижение ранга
+			chol.Update(common_funcs.ScalarMult(1/math.Sqrt(deltaGamma), gamma))
+			deltaBDelta = common_funcs.DotProduct(delta, bDelta)
+			if !chol.Downdate(common_funcs.ScalarMult(1/math.Sqrt(deltaBDelta), bDelta)) {
+				// Потеря положительной определенности из-за округлений - начинаем заново
+				reset()
+			}
+		}
+
+		// Переходим к следующей итерации
+		x = xNext
+		grad = gradNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Printf("Квазиньютоновский метод (BFGS, %s) достиг максимального числа итераций.\n", form)
+	}
+	return x, iter, damped, skipped // Возвращаем результат
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden,armijo", "одномерный поиск через запятую: "+common_funcs.LineSearchUsage)
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 500                                 // Макс. итераций
+	resetInterval := 5 * len(startPoint)           // Интервал сброса матрицы (например, каждые 5*n итераций)
+
+	baseline := common_funcs.CostBaseline{}
+	for _, lineSearch := range lineSearches {
+		for _, form := range []bfgsForm{inverseForm, factorForm} {
+			// Вызываем метод, считая вычисления функции и производных
+			counted := common_funcs.NewCountingObjective(obj)
+			minX, iterations, damped, skipped := quasiNewtonBFGS(counted, startPoint, epsilon, maxIter, lineSearch, resetInterval, form, true)
+			minF := obj.Value(minX) // Значение функции в минимуме
+
+			// Выводим результаты
+			fmt.Printf("\nКвазиньютоновский метод (BFGS, %s, %s):\n", form, lineSearch)
+			fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+			fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+			fmt.Printf("Количество итераций: %d\n", iterations)
+			fmt.Printf("Демпфированных обновлений: %d, пропущенных: %d\n", damped, skipped)
+			fmt.Println("Стоимость:", counted)
+			if saving := baseline.Compare(form.String(), lineSearch, counted); saving != "" {
+				fmt.Println("По сравнению с золотым сечением:", saving)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"optimizationMethodsTask4/autodiff"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/preconditioner"
+	"os"
+)
+
+// quasiNewtonLBFGS реализует Квазиньютоновский метод L-BFGS (BFGS с ограниченной памятью).
+// Вместо матрицы H хранятся m последних пар (delta, gamma), а направление
+// d = -H·grad вычисляется двухцикловой рекурсией за O(m·n) операций, поэтому
+// метод годится для задач с десятками тысяч переменных.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// resetInterval - интервал для очистки памяти (0 - не очищать).
+// memory - количество хранимых пар m.
+// Возвращает найденную точку минимума и количество итераций.
+func quasiNewtonLBFGS(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, resetInterval, memory int) ([]float64, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	mem := preconditioner.NewLBFGSMemory(memory)
+
+	grad := obj.Gradient(x) // Начальный градиент
+
+	// Основной цикл метода
+	for iter < maxIter {
+		gradNorm := common_funcs.VectorNorm(grad) // Норма градиента
+
+		// Критерий остановки
+		if gradNorm < epsilon {
+			break
+		}
+
+		// Периодическая очистка памяти (для стабильности)
+		if resetInterval > 0 && iter%resetInterval == 0 && iter > 0 {
+			mem.Reset()
+		}
+
+		// 1. Вычисляем направление спуска двухцикловой рекурсией: d = -H * grad
+		direction := common_funcs.ScalarMult(-1.0, mem.Apply(grad))
+
+		// 2. Ищем шаг alpha с помощью одномерного поиска
+		alpha := lineSearch.Step(obj, x, direction, grad)
+
+		// 3. Обновляем точку: x_next = x + alpha * d
+		delta := common_funcs.ScalarMult(alpha, direction) // delta = x_next - x
+		xNext := common_funcs.VectorAdd(x, delta)
+
+		// 4. Вычисляем новый градиент и gamma = grad_next - grad
+		gradNext := obj.Gradient(xNext)
+		gamma := common_funcs.VectorSub(gradNext, grad)
+
+		// 5. Запоминаем пару, если выполнено условие кривизны sᵀy > 0
+		mem.Push(delta, gamma)
+
+		// Переходим к следующей итерации
+		x = xNext
+		grad = gradNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Квазиньютоновский метод (L-BFGS) достиг максимального числа итераций.")
+	}
+	return x, iter // Возвращаем результат
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden,armijo", "одномерный поиск через запятую: "+common_funcs.LineSearchUsage)
+	rosenbrock := flag.Int("rosenbrock", 0, "размерность расширенной функции Розенброка (четная, 0 - не использовать)")
+	memory := flag.Int("m", 5, "количество хранимых пар (delta, gamma)")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164, формула из командной строки/файла
+	// или расширенная функция Розенброка большой размерности
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	switch {
+	case parsed != nil:
+		obj = parsed
+	case *rosenbrock > 0:
+		if *rosenbrock%2 != 0 {
+			fmt.Println("Размерность функции Розенброка должна быть четной:", *rosenbrock)
+			os.Exit(2)
+		}
+		obj = autodiff.NewReverseObjective(*rosenbrock, autodiff.ExtendedRosenbrock[autodiff.Var])
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 1000                                // Макс. итераций
+	resetInterval := 0                             // Без очистки памяти: старые пары вытесняются сами
+
+	baseline := common_funcs.CostBaseline{}
+	for _, lineSearch := range lineSearches {
+		// Вызываем метод, считая вычисления функции и производных
+		counted := common_funcs.NewCountingObjective(obj)
+		minX, iterations := quasiNewtonLBFGS(counted, startPoint, epsilon, maxIter, lineSearch, resetInterval, *memory)
+		minF := obj.Value(minX) // Значение функции в минимуме
+
+		// Выводим результаты
+		fmt.Printf("\nКвазиньютоновский метод (L-BFGS, m = %d, %s):\n", *memory, lineSearch)
+		if len(minX) <= 10 {
+			fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+		}
+		fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+		fmt.Printf("Количество итераций: %d\n", iterations)
+		fmt.Println("Стоимость:", counted)
+		if saving := baseline.Compare("L-BFGS", lineSearch, counted); saving != "" {
+			fmt.Println("По сравнению с золотым сечением:", saving)
+		}
+	}
+}
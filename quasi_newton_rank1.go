@@ -1,20 +1,23 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"os"
 )
 
 // quasiNewtonRank1 реализует Квазиньютоновский метод с поправкой ранга 1.
+// obj - целевая функция.
 // startPoint - начальная точка.
 // epsilon - точность (норма градиента).
 // maxIter - максимальное количество итераций.
-// lineSearchMaxAlpha - верхняя граница для поиска шага alpha.
-// lineSearchTol - точность для метода золотого сечения.
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
 // resetInterval - интервал для сброса H к единичной матрице (0 - не сбрасывать).
 // Возвращает найденную точку минимума и количество итераций.
-func quasiNewtonRank1(startPoint []float64, epsilon float64, maxIter int, lineSearchMaxAlpha, lineSearchTol float64, resetInterval int) ([]float64, int) {
+func quasiNewtonRank1(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, resetInterval int) ([]float64, int) {
 	x := make([]float64, len(startPoint))
 	copy(x, startPoint)
 	iter := 0
@@ -23,7 +26,7 @@ func quasiNewtonRank1(startPoint []float64, epsilon float64, maxIter int, lineSe
 	// H - аппроксимация обратной матрицы Гессе
 	H := common_funcs.IdentityMatrix(dim) // Начинаем с единичной матрицы
 
-	grad := common_funcs.GradF(x) // Начальный градиент
+	grad := obj.Gradient(x) // Начальный градиент
 
 	// Основной цикл метода
 	for iter < maxIter {
@@ -40,32 +43,12 @@ func quasiNewtonRank1(startPoint []float64, epsilon float64, maxIter int, lineSe
 			// fmt.Println("Сброс H на итерации", iter)
 		}
 
-		// 1. Вычисляем направление спуска: d = -H * grad
-		direction := common_funcs.ScalarMult(-1.0, common_funcs.MatrixVectorMult(H, grad))
-
-		// 2. Ищем шаг alpha с помощью одномерного поиска
-		alpha := common_funcs.GoldenSectionSearch(x, direction, 0.0, lineSearchMaxAlpha, lineSearchTol)
-
-		// 3. Обновляем точку: x_next = x + alpha * d
-		xNext := common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
-
-		// 4. Вычисляем новый градиент
-		gradNext := common_funcs.GradF(xNext)
-
-		// 5. Вычисляем векторы delta и gamma для обновления H
-		delta := common_funcs.ScalarMult(alpha, direction) // delta = x_next - x
-		gamma := common_funcs.VectorSub(gradNext, grad)    // gamma = grad_next - grad
+		// 1-5. Шаг по направлению d = -H * grad, векторы delta и gamma
+		xNext, gradNext, delta, gamma, _ := quasiNewtonStep(obj, x, grad, H, lineSearch)
 
 		// 6. Обновляем матрицу H по формуле Ранга 1
-		Hgamma := common_funcs.MatrixVectorMult(H, gamma)
-		deltaMinusHgamma := common_funcs.VectorSub(delta, Hgamma)
-		denominator := common_funcs.DotProduct(deltaMinusHgamma, gamma)
-
-		// Проверка знаменателя, чтобы избежать деления на ноль или нестабильности
-		if math.Abs(denominator) > 1e-9 {
-			outerProdTerm := common_funcs.OuterProduct(deltaMinusHgamma, deltaMinusHgamma)
-			updateTerm := common_funcs.MatrixScalarMult(1.0/denominator, outerProdTerm)
-			H = common_funcs.MatrixAdd(H, updateTerm) // H_next = H + updateTerm
+		if Hnext, ok := rank1Update(H, delta, gamma); ok {
+			H = Hnext // H_next = H + updateTerm
 		} else {
 			// fmt.Println("Малый знаменатель при обновлении H на итерации", iter, ", пропуск обновления.")
 			// Если знаменатель мал, обновление может быть нестабильным, пропускаем его
@@ -86,21 +69,241 @@ func quasiNewtonRank1(startPoint []float64, epsilon float64, maxIter int, lineSe
 	return x, iter // Возвращаем результат
 }
 
+// quasiNewtonStep выполняет общую для всех квазиньютоновских методов часть итерации:
+// направление d = -H * grad, одномерный поиск шага alpha, новую точку и градиент,
+// а также векторы delta = x_next - x и gamma = grad_next - grad.
+// Последним возвращается B·delta = -alpha * grad (B = H⁻¹), нужное формулам в B-форме.
+func quasiNewtonStep(obj common_funcs.Objective, x, grad []float64, H common_funcs.Matrix, lineSearch common_funcs.LineSearch) (xNext, gradNext, delta, gamma, bDelta []float64) {
+	// Вычисляем направление спуска: d = -H * grad
+	direction := common_funcs.ScalarMult(-1.0, common_funcs.MatrixVectorMult(H, grad))
+
+	// Ищем шаг alpha с помощью одномерного поиска
+	alpha := lineSearch.Step(obj, x, direction, grad)
+
+	// Обновляем точку: x_next = x + alpha * d
+	xNext = common_funcs.VectorAdd(x, common_funcs.ScalarMult(alpha, direction))
+
+	// Вычисляем новый градиент
+	gradNext = obj.Gradient(xNext)
+
+	delta = common_funcs.ScalarMult(alpha, direction) // delta = x_next - x
+	gamma = common_funcs.VectorSub(gradNext, grad)    // gamma = grad_next - grad
+	bDelta = common_funcs.ScalarMult(-alpha, grad)    // B * delta = -alpha * grad
+	return xNext, gradNext, delta, gamma, bDelta
+}
+
+// rank1Update вычисляет H_next = H + (delta - H gamma)(delta - H gamma)ᵀ / ((delta - H gamma)ᵀ gamma).
+// Возвращает false, если знаменатель по модулю меньше 1e-9 и обновление нестабильно.
+func rank1Update(H common_funcs.Matrix, delta, gamma []float64) (common_funcs.Matrix, bool) {
+	Hgamma := common_funcs.MatrixVectorMult(H, gamma)
+	deltaMinusHgamma := common_funcs.VectorSub(delta, Hgamma)
+	denominator := common_funcs.DotProduct(deltaMinusHgamma, gamma)
+
+	// Проверка знаменателя, чтобы избежать деления на ноль или нестабильности
+	if math.Abs(denominator) <= 1e-9 {
+		return nil, false
+	}
+	outerProdTerm := common_funcs.OuterProduct(deltaMinusHgamma, deltaMinusHgamma)
+	updateTerm := common_funcs.MatrixScalarMult(1.0/denominator, outerProdTerm)
+	return common_funcs.MatrixAdd(H, updateTerm), true
+}
+
+// broydenUpdate - формула обновления из однопараметрического семейства Бройдена
+// B_next = B - B s sᵀB / sᵀBs + y yᵀ / sᵀy + φ (sᵀBs) v vᵀ, v = y / sᵀy - Bs / sᵀBs,
+// где s = delta, y = gamma. φ = 0 дает BFGS, φ = 1 - DFP; при φ ∈ [0, 1] и sᵀy > 0
+// матрица остается положительно определенной. SR1 получается при
+// φ = sᵀy / (sᵀy - sᵀBs), зависящем от итерации, поэтому задается отдельным признаком.
+type broydenUpdate struct {
+	name string  // Название формулы для вывода
+	phi  float64 // Параметр семейства φ
+	sr1  bool    // Использовать SR1 (φ вычисляется на каждой итерации)
+}
+
+var (
+	dfpUpdate  = broydenUpdate{name: "DFP", phi: 1}
+	bfgsUpdate = broydenUpdate{name: "BFGS", phi: 0}
+	sr1Update  = broydenUpdate{name: "SR1", sr1: true}
+)
+
+// quasiNewtonBroyden реализует Квазиньютоновский метод с обновлением из семейства Бройдена.
+// Хранится аппроксимация обратной матрицы Гессе H; параметр φ семейства для B
+// переводится в параметр θ той же формулы для H: θ = (1-φ) / (1 - φ + φμ),
+// μ = (sᵀBs)(yᵀHy) / (sᵀy)². Обновление пропускается (H не меняется), если
+// нарушено условие кривизны sᵀy > 0 (для φ ∈ [0, 1]) или знаменатель формулы мал.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций.
+// lineSearch - одномерный поиск шага alpha (методы one_dim, Свенн, Армихо, Вольфе).
+// resetInterval - интервал для сброса H к единичной матрице (0 - не сбрасывать).
+// update - формула обновления (dfpUpdate, bfgsUpdate, sr1Update или свое φ).
+// verbose - печатать на каждой итерации, какое обновление применено или пропущено.
+// Возвращает найденную точку минимума, количество итераций и количество пропущенных обновлений.
+func quasiNewtonBroyden(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, lineSearch common_funcs.LineSearch, resetInterval int, update broydenUpdate, verbose bool) ([]float64, int, int) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	dim := len(startPoint)
+	skipped := 0
+
+	// H - аппроксимация обратной матрицы Гессе
+	H := common_funcs.IdentityMatrix(dim) // Начинаем с единичной матрицы
+
+	grad := obj.Gradient(x) // Начальный градиент
+
+	// Основной цикл метода
+	for iter < maxIter {
+		gradNorm := common_funcs.VectorNorm(grad) // Норма градиента
+
+		// Критерий остановки
+		if gradNorm < epsilon {
+			break
+		}
+
+		// Периодический сброс H к единичной матрице (для стабильности)
+		if resetInterval > 0 && iter%resetInterval == 0 && iter > 0 {
+			H = common_funcs.IdentityMatrix(dim)
+			if verbose {
+				fmt.Printf("  Итерация %d: сброс H к единичной матрице\n", iter)
+			}
+		}
+
+		// 1-5. Шаг по направлению d = -H * grad, векторы delta и gamma
+		xNext, gradNext, delta, gamma, bDelta := quasiNewtonStep(obj, x, grad, H, lineSearch)
+
+		// 6. Обновляем матрицу H по выбранной формуле семейства
+		var applied string
+		var ok bool
+		if update.sr1 {
+			H, ok = rank1UpdateOrKeep(H, delta, gamma)
+			applied = "SR1"
+		} else {
+			H, ok = broydenFamilyUpdate(H, delta, gamma, bDelta, update.phi)
+			applied = fmt.Sprintf("%s (φ = %g)", update.name, update.phi)
+		}
+		if !ok {
+			skipped++
+		}
+		if verbose {
+			if ok {
+				fmt.Printf("  Итерация %d: применено обновление %s\n", iter, applied)
+			} else {
+				fmt.Printf("  Итерация %d: обновление %s пропущено\n", iter, applied)
+			}
+		}
+
+		// Переходим к следующей итерации
+		x = xNext
+		grad = gradNext
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Printf("Квазиньютоновский метод (%s) достиг максимального числа итераций.\n", update.name)
+	}
+	return x, iter, skipped // Возвращаем результат
+}
+
+// rank1UpdateOrKeep применяет поправку ранга 1, а при малом знаменателе оставляет H без изменений.
+func rank1UpdateOrKeep(H common_funcs.Matrix, delta, gamma []float64) (common_funcs.Matrix, bool) {
+	if Hnext, ok := rank1Update(H, delta, gamma); ok {
+		return Hnext, true
+	}
+	return H, false
+}
+
+// broydenFamilyUpdate обновляет H по формуле семейства Бройдена с параметром phi:
+// H_next = H - H y yᵀH / yᵀHy + s sᵀ / sᵀy + θ (yᵀHy) w wᵀ, w = s / sᵀy - Hy / yᵀHy.
+// Возвращает H без изменений и false, если обновление не определено или
+// нарушает условие кривизны.
+func broydenFamilyUpdate(H common_funcs.Matrix, delta, gamma, bDelta []float64, phi float64) (common_funcs.Matrix, bool) {
+	// Пороги относительные: вблизи минимума delta и gamma малы, и абсолютный
+	// порог пропускал бы почти все обновления
+	deltaGamma := common_funcs.DotProduct(delta, gamma)
+	scale := common_funcs.VectorNorm(delta) * common_funcs.VectorNorm(gamma)
+	if deltaGamma <= 1e-10*scale && phi >= 0 && phi <= 1 {
+		return H, false // Нарушено условие кривизны
+	}
+	Hgamma := common_funcs.MatrixVectorMult(H, gamma)
+	gammaHgamma := common_funcs.DotProduct(gamma, Hgamma)
+	deltaBDelta := common_funcs.DotProduct(delta, bDelta)
+	if math.Abs(deltaGamma) <= 1e-10*scale || gammaHgamma <= 0 {
+		return H, false
+	}
+	thetaDenominator := 1 - phi + phi*deltaBDelta*gammaHgamma/(deltaGamma*deltaGamma)
+	if math.Abs(thetaDenominator) <= 1e-9 {
+		return H, false
+	}
+	theta := (1 - phi) / thetaDenominator
+	w := common_funcs.VectorSub(common_funcs.ScalarMult(1/deltaGamma, delta), common_funcs.ScalarMult(1/gammaHgamma, Hgamma))
+	H = common_funcs.MatrixAdd(H, common_funcs.MatrixScalarMult(-1/gammaHgamma, common_funcs.OuterProduct(Hgamma, Hgamma)))
+	H = common_funcs.MatrixAdd(H, common_funcs.MatrixScalarMult(1/deltaGamma, common_funcs.OuterProduct(delta, delta)))
+	H = common_funcs.MatrixAdd(H, common_funcs.MatrixScalarMult(theta*gammaHgamma, common_funcs.OuterProduct(w, w)))
+	return H, true
+}
+
 func main() {
-	startPoint := []float64{0.0, 0.0, 0.0} // Начальная точка 3D
-	epsilon := 1e-5                        // Точность
-	maxIter := 500                         // Макс. итераций
-	lineSearchMaxAlpha := 1.0              // Макс. alpha для GSS
-	lineSearchTol := 1e-6                  // Точность для GSS
-	resetInterval := 5 * len(startPoint)   // Интервал сброса H (например, каждые 5*n итераций)
-
-	// Вызываем метод
-	minX, iterations := quasiNewtonRank1(startPoint, epsilon, maxIter, lineSearchMaxAlpha, lineSearchTol, resetInterval)
-	minF := common_funcs.F(minX) // Значение функции в минимуме
-
-	// Выводим результаты
-	fmt.Println("\nКвазиньютоновский метод (Ранг 1):")
-	fmt.Printf("Найденный минимум x: [%.6f, %.6f, %.6f]\n", minX[0], minX[1], minX[2])
-	fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
-	fmt.Printf("Количество итераций: %d\n", iterations)
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	lineSearchNames := flag.String("linesearch", "golden,armijo", "одномерный поиск через запятую: "+common_funcs.LineSearchUsage)
+	phi := flag.Float64("phi", 0.5, "параметр φ семейства Бройдена для дополнительного запуска")
+	verbose := flag.Bool("log", true, "печатать применённое на каждой итерации обновление")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+	lineSearches, err := common_funcs.ParseLineSearches(*lineSearchNames)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 500                                 // Макс. итераций
+	resetInterval := 5 * len(startPoint)           // Интервал сброса H (например, каждые 5*n итераций)
+
+	baseline := common_funcs.CostBaseline{}
+	for _, lineSearch := range lineSearches {
+		// Вызываем метод, считая вычисления функции и производных
+		counted := common_funcs.NewCountingObjective(obj)
+		minX, iterations := quasiNewtonRank1(counted, startPoint, epsilon, maxIter, lineSearch, resetInterval)
+		minF := obj.Value(minX) // Значение функции в минимуме
+
+		// Выводим результаты
+		fmt.Printf("\nКвазиньютоновский метод (Ранг 1, %s):\n", lineSearch)
+		fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+		fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+		fmt.Printf("Количество итераций: %d\n", iterations)
+		fmt.Println("Стоимость:", counted)
+		if saving := baseline.Compare("Ранг 1", lineSearch, counted); saving != "" {
+			fmt.Println("По сравнению с золотым сечением:", saving)
+		}
+
+		// --- Семейство Бройдена: DFP, BFGS, SR1 и промежуточное φ ---
+		updates := []broydenUpdate{dfpUpdate, bfgsUpdate, sr1Update, {name: "Бройден", phi: *phi}}
+		for _, update := range updates {
+			fmt.Printf("\nКвазиньютоновский метод (%s, %s):\n", update.name, lineSearch)
+			counted := common_funcs.NewCountingObjective(obj)
+			minX, iterations, skipped := quasiNewtonBroyden(counted, startPoint, epsilon, maxIter, lineSearch, resetInterval, update, *verbose)
+			minF := obj.Value(minX)
+			fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+			fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+			fmt.Printf("Количество итераций: %d\n", iterations)
+			fmt.Printf("Пропущенных обновлений: %d\n", skipped)
+			fmt.Println("Стоимость:", counted)
+			if saving := baseline.Compare(update.name, lineSearch, counted); saving != "" {
+				fmt.Println("По сравнению с золотым сечением:", saving)
+			}
+		}
+	}
 }
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/trust_region"
+	"os"
+)
+
+// sr1SkipRatio - параметр r правила пропуска SR1: обновление выполняется,
+// только если |sᵀ(y - Bs)| ≥ r‖s‖‖y - Bs‖ (Нокедал, Райт, (6.26)).
+const sr1SkipRatio = 1e-8
+
+// sr1TrustRegion реализует Квазиньютоновский метод SR1 с доверительной областью.
+// Поправка ранга 1 не сохраняет положительную определенность B, но внутри
+// доверительной области это не мешает: подзадача решается и для неопределенной
+// модели, а SR1 приближает Гессиан точнее, чем BFGS. В отличие от quasiNewtonRank1
+// хранится сама аппроксимация Гессиана B, знаменатель проверяется относительным
+// правилом, а при малом знаменателе обновление пропускается без сброса B.
+// B обновляется и после отвергнутого шага - пара (s, y) все равно несет информацию о кривизне.
+// obj - целевая функция.
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций (пробных шагов).
+// initialRadius - начальный радиус доверительной области.
+// maxRadius - наибольший допустимый радиус.
+// subproblem - решатель подзадачи (должен допускать неопределенную B).
+// Возвращает найденную точку минимума, количество итераций,
+// количество пропущенных обновлений и историю радиусов.
+func sr1TrustRegion(obj common_funcs.Objective, startPoint []float64, epsilon float64, maxIter int, initialRadius, maxRadius float64, subproblem trust_region.Subproblem) ([]float64, int, int, []float64) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	dim := len(startPoint)
+	radius := initialRadius
+	skipped := 0
+	var radii []float64 // Радиус доверительной области на каждой итерации
+
+	// B - аппроксимация матрицы Гессе
+	B := common_funcs.IdentityMatrix(dim) // Начинаем с единичной матрицы
+
+	f := obj.Value(x)
+	grad := obj.Gradient(x)
+
+	// Основной цикл метода
+	for iter < maxIter {
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			break
+		}
+		radii = append(radii, radius)
+
+		// 1. Решаем подзадачу для модели с матрицей B
+		step := subproblem(grad, B, radius)
+		stepNorm := common_funcs.VectorNorm(step)
+		bStep := common_funcs.MatrixVectorMult(B, step)
+
+		// 2. Сравниваем предсказанное моделью и фактическое уменьшение функции
+		pred := trust_region.ModelReduction(grad, step, bStep)
+		xNext := common_funcs.VectorAdd(x, step)
+		fNext := obj.Value(xNext)
+		gradNext := obj.Gradient(xNext)
+		_, newRadius, accept := trust_region.UpdateRadius(f-fNext, pred, radius, stepNorm, maxRadius)
+
+		// 3. Обновляем B по формуле SR1 с относительным правилом пропуска
+		gamma := common_funcs.VectorSub(gradNext, grad)  // y = grad_next - grad
+		residual := common_funcs.VectorSub(gamma, bStep) // y - B s
+		denominator := common_funcs.DotProduct(step, residual)
+		if math.Abs(denominator) >= sr1SkipRatio*stepNorm*common_funcs.VectorNorm(residual) && denominator != 0 {
+			// B_next = B + (y - Bs)(y - Bs)ᵀ / sᵀ(y - Bs)
+			B = common_funcs.MatrixAdd(B, common_funcs.MatrixScalarMult(1.0/denominator, common_funcs.OuterProduct(residual, residual)))
+		} else {
+			skipped++
+		}
+
+		// 4. Принимаем шаг, только если модель достаточно хорошо предсказала уменьшение
+		if accept {
+			x = xNext
+			f = fNext
+			grad = gradNext
+		}
+		radius = newRadius
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Метод SR1 с доверительной областью достиг максимального числа итераций.")
+	}
+	return x, iter, skipped, radii // Возвращаем результат
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 500                                 // Макс. итераций
+	initialRadius := 1.0                           // Начальный радиус доверительной области
+	maxRadius := 100.0                             // Максимальный радиус
+
+	subproblems := []struct {
+		name  string
+		solve trust_region.Subproblem
+	}{
+		{"Море-Соренсен", trust_region.MoreSorensen},
+		{"Штайхауг-Тоинт", trust_region.Steihaug},
+	}
+	for _, sp := range subproblems {
+		// Вызываем метод
+		minX, iterations, skipped, radii := sr1TrustRegion(obj, startPoint, epsilon, maxIter, initialRadius, maxRadius, sp.solve)
+		minF := obj.Value(minX) // Значение функции в минимуме
+
+		// Выводим результаты
+		fmt.Printf("\nМетод SR1 с доверительной областью (%s):\n", sp.name)
+		fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+		fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+		fmt.Printf("Количество итераций: %d\n", iterations)
+		fmt.Printf("Пропущенных обновлений: %d\n", skipped)
+		fmt.Printf("История радиусов: %.4g\n", radii)
+	}
+}
@@ -0,0 +1,114 @@
+package trust_region
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// newtonPoint вычисляет шаг Ньютона -B⁻¹g. Для не положительно определенной B
+// используется модифицированное разложение Холецкого (Гилл-Мюррей), чтобы
+// шаг оставался направлением спуска модели с сохраненной информацией о кривизне;
+// в этом случае возвращается false.
+func newtonPoint(g []float64, b common_funcs.Matrix) ([]float64, bool) {
+	minusG := common_funcs.ScalarMult(-1.0, g)
+	if c, ok := b.Cholesky(); ok {
+		return c.Solve(minusG), true
+	}
+	solve, _ := common_funcs.ModifyHessian(b, common_funcs.GillMurrayCholesky)
+	return solve(minusG), false
+}
+
+// notWorseThanCauchy возвращает шаг p, если он уменьшает модель не меньше точки
+// Коши, и точку Коши иначе. Путь через модифицированный шаг Ньютона не гарантирует
+// уменьшения модели с неопределенной B хотя бы на величину, даваемую точкой Коши,
+// а от этого зависит сходимость метода доверительной области.
+func notWorseThanCauchy(p, g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	cauchy := CauchyPoint(g, b, radius)
+	if ModelReduction(g, p, common_funcs.MatrixVectorMult(b, p)) >= ModelReduction(g, cauchy, common_funcs.MatrixVectorMult(b, cauchy)) {
+		return p
+	}
+	return cauchy
+}
+
+// steepestPoint - неограниченный минимум модели вдоль антиградиента
+// p_U = -(gᵀg / gᵀBg)·g. Возвращает false при неположительной кривизне вдоль g.
+func steepestPoint(g []float64, b common_funcs.Matrix) ([]float64, bool) {
+	gBg := common_funcs.DotProduct(g, common_funcs.MatrixVectorMult(b, g))
+	if gBg <= 0 {
+		return nil, false
+	}
+	return common_funcs.ScalarMult(-common_funcs.DotProduct(g, g)/gBg, g), true
+}
+
+// Dogleg решает подзадачу методом "собачьей ноги": путь идет от нуля к минимуму
+// вдоль антиградиента p_U, затем к шагу Ньютона p_B, и обрезается границей области.
+// При отрицательной кривизне вдоль антиградиента возвращается точка Коши на границе,
+// а при не положительно определенной B - лучший из шага по пути и точки Коши.
+func Dogleg(g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	pU, ok := steepestPoint(g, b)
+	if !ok {
+		return CauchyPoint(g, b, radius)
+	}
+	pB, positive := newtonPoint(g, b)
+	if !positive {
+		return notWorseThanCauchy(doglegPath(pU, pB, radius), g, b, radius)
+	}
+	return doglegPath(pU, pB, radius)
+}
+
+// doglegPath находит пересечение пути 0 → p_U → p_B с границей области.
+func doglegPath(pU, pB []float64, radius float64) []float64 {
+	if common_funcs.VectorNorm(pB) <= radius {
+		return pB
+	}
+	pUNorm := common_funcs.VectorNorm(pU)
+	if pUNorm >= radius {
+		return common_funcs.ScalarMult(radius/pUNorm, pU)
+	}
+	// Второй отрезок: p = p_U + τ(p_B - p_U), ‖p‖ = Δ
+	d := common_funcs.VectorSub(pB, pU)
+	tau := boundaryStep(pU, d, radius)
+	return common_funcs.VectorAdd(pU, common_funcs.ScalarMult(tau, d))
+}
+
+// DoubleDogleg - вариант Денниса-Мея: второй излом пути ставится в точке η·p_B
+// (η = 0.8γ + 0.2, γ = (gᵀg)² / (gᵀBg · gᵀB⁻¹g) ≤ 1), ближе к шагу Ньютона,
+// поэтому путь раньше сворачивает в сторону Ньютона, чем в обычном Dogleg.
+// Для не положительно определенной B возвращается лучший из шага по пути и точки Коши.
+func DoubleDogleg(g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	pU, ok := steepestPoint(g, b)
+	if !ok {
+		return CauchyPoint(g, b, radius)
+	}
+	pB, positive := newtonPoint(g, b)
+	if !positive {
+		return notWorseThanCauchy(doubleDoglegPath(g, b, pU, pB, radius), g, b, radius)
+	}
+	return doubleDoglegPath(g, b, pU, pB, radius)
+}
+
+// doubleDoglegPath находит пересечение пути 0 → p_U → η·p_B → p_B с границей области.
+func doubleDoglegPath(g []float64, b common_funcs.Matrix, pU, pB []float64, radius float64) []float64 {
+	pBNorm := common_funcs.VectorNorm(pB)
+	if pBNorm <= radius {
+		return pB
+	}
+	gg := common_funcs.DotProduct(g, g)
+	gBg := common_funcs.DotProduct(g, common_funcs.MatrixVectorMult(b, g))
+	gBinvg := -common_funcs.DotProduct(g, pB) // gᵀB⁻¹g
+	gamma := 1.0
+	if gBinvg > 0 {
+		gamma = math.Min(gg*gg/(gBg*gBinvg), 1)
+	}
+	eta := 0.8*gamma + 0.2
+	if eta*pBNorm <= radius {
+		return common_funcs.ScalarMult(radius/pBNorm, pB)
+	}
+	pUNorm := common_funcs.VectorNorm(pU)
+	if pUNorm >= radius {
+		return common_funcs.ScalarMult(radius/pUNorm, pU)
+	}
+	d := common_funcs.VectorSub(common_funcs.ScalarMult(eta, pB), pU)
+	tau := boundaryStep(pU, d, radius)
+	return common_funcs.VectorAdd(pU, common_funcs.ScalarMult(tau, d))
+}
@@ -0,0 +1,137 @@
+package trust_region
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// MoreSorensen решает подзадачу доверительной области практически точно методом
+// Море-Соренсена: ищется λ ≥ max(0, -λ₁) такое, что (B + λI)p = -g и ‖p‖ = Δ,
+// итерациями Ньютона по λ для уравнения 1/‖p(λ)‖ = 1/Δ с разложением Холецкого.
+// Работает и для неопределенных B, включая "трудный случай", когда g
+// ортогонален собственному вектору наименьшего собственного значения λ₁.
+func MoreSorensen(g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	n := len(g)
+	gNorm := common_funcs.VectorNorm(g)
+	values, vectors := common_funcs.SymmetricEigen(b)
+	k := 0
+	for i := range values {
+		if values[i] < values[k] {
+			k = i
+		}
+	}
+	lambda1 := values[k]
+	v1 := make([]float64, n)
+	for i := range v1 {
+		v1[i] = vectors[i][k]
+	}
+
+	// Если B положительно определена и шаг Ньютона внутри области, он и есть решение
+	if lambda1 > 0 {
+		if c, ok := b.Cholesky(); ok {
+			p := c.Solve(common_funcs.ScalarMult(-1.0, g))
+			if common_funcs.VectorNorm(p) <= radius {
+				return p
+			}
+		}
+	}
+
+	lambdaL := math.Max(0, -lambda1)
+	lambdaU := gNorm/radius + b.Norm1() // При λ ≥ λU заведомо ‖p(λ)‖ ≤ Δ
+
+	// Трудный случай: g (почти) ортогонален собственному вектору v₁, и даже при
+	// λ = -λ₁ шаг не достигает границы - добавляем к нему компоненту вдоль v₁
+	if lambda1 <= 0 && math.Abs(common_funcs.DotProduct(g, v1)) <= 1e-10*math.Max(1, gNorm) {
+		p := pseudoStep(values, vectors, g, lambdaL)
+		if common_funcs.VectorNorm(p) < radius {
+			tau := boundaryStep(p, v1, radius)
+			return common_funcs.VectorAdd(p, common_funcs.ScalarMult(tau, v1))
+		}
+	}
+
+	lambda := safeguardLambda(lambdaL, lambdaU)
+	var p []float64
+	for iter := 0; iter < 50; iter++ {
+		shifted := b.Copy()
+		for i := 0; i < n; i++ {
+			shifted[i][i] += lambda
+		}
+		c, ok := shifted.Cholesky()
+		if !ok {
+			// λ слишком мал: B + λI не положительно определена
+			lambdaL = lambda
+			lambda = safeguardLambda(lambdaL, lambdaU)
+			continue
+		}
+		p = c.Solve(common_funcs.ScalarMult(-1.0, g))
+		pNorm := common_funcs.VectorNorm(p)
+		if math.Abs(pNorm-radius) <= 1e-8*radius {
+			return p
+		}
+		if pNorm < radius {
+			lambdaU = lambda
+		} else {
+			lambdaL = lambda
+		}
+		// Шаг Ньютона по λ: q = L⁻¹p, λ ← λ + (‖p‖/‖q‖)²·(‖p‖ - Δ)/Δ
+		q := forwardSolve(c.L, p)
+		qNorm := common_funcs.VectorNorm(q)
+		next := lambda + (pNorm/qNorm)*(pNorm/qNorm)*(pNorm-radius)/radius
+		if next <= lambdaL || next >= lambdaU {
+			next = safeguardLambda(lambdaL, lambdaU)
+		}
+		lambda = next
+	}
+	if p == nil {
+		return CauchyPoint(g, b, radius)
+	}
+	if pNorm := common_funcs.VectorNorm(p); pNorm > radius {
+		p = common_funcs.ScalarMult(radius/pNorm, p)
+	}
+	return p
+}
+
+// safeguardLambda выбирает новое λ внутри интервала [λL, λU], когда шаг Ньютона
+// по λ вышел за его пределы.
+func safeguardLambda(lambdaL, lambdaU float64) float64 {
+	return math.Max(math.Sqrt(lambdaL*lambdaU), lambdaL+0.01*(lambdaU-lambdaL))
+}
+
+// pseudoStep вычисляет p = -Σ (qᵢᵀg)/(λᵢ + λ)·qᵢ по собственным парам B,
+// пропуская слагаемые с λᵢ + λ ≈ 0 (псевдообращение B + λI).
+func pseudoStep(values []float64, vectors common_funcs.Matrix, g []float64, lambda float64) []float64 {
+	n := len(g)
+	maxAbs := 0.0
+	for _, v := range values {
+		maxAbs = math.Max(maxAbs, math.Abs(v))
+	}
+	p := make([]float64, n)
+	for j := range values {
+		denom := values[j] + lambda
+		if math.Abs(denom) <= 1e-12*math.Max(1, maxAbs) {
+			continue
+		}
+		coef := 0.0
+		for i := 0; i < n; i++ {
+			coef += vectors[i][j] * g[i]
+		}
+		coef /= denom
+		for i := 0; i < n; i++ {
+			p[i] -= coef * vectors[i][j]
+		}
+	}
+	return p
+}
+
+// forwardSolve решает нижнетреугольную систему L q = b.
+func forwardSolve(l common_funcs.Matrix, b []float64) []float64 {
+	q := make([]float64, len(b))
+	for i := range b {
+		q[i] = b[i]
+		for j := 0; j < i; j++ {
+			q[i] -= l[i][j] * q[j]
+		}
+		q[i] /= l[i][i]
+	}
+	return q
+}
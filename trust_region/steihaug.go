@@ -0,0 +1,78 @@
+package trust_region
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// CGStop - причина остановки внутреннего цикла сопряженных градиентов.
+type CGStop int
+
+const (
+	CGConverged         CGStop = iota // Невязка стала меньше допуска (шаг внутри области)
+	CGNegativeCurvature               // Найдено направление неположительной кривизны
+	CGBoundary                        // Очередной шаг вышел на границу области
+	CGMaxIter                         // Исчерпан лимит внутренних итераций
+)
+
+func (s CGStop) String() string {
+	switch s {
+	case CGConverged:
+		return "сходимость"
+	case CGNegativeCurvature:
+		return "отрицательная кривизна"
+	case CGBoundary:
+		return "граница области"
+	case CGMaxIter:
+		return "лимит итераций"
+	}
+	return "неизвестно"
+}
+
+// SteihaugCG приближенно решает подзадачу доверительной области усеченным
+// методом сопряженных градиентов Штайхауга-Тоинта. Матрица модели задается только
+// произведением hv(d) = B·d, поэтому B никогда не строится и не раскладывается.
+// Внутренний цикл - итерации common_funcs.CGIteration для B·p = -g из p = 0
+// (точный шаг α = rᵀr / dᵀBd, β = r₊ᵀr₊ / rᵀr), как в LinearCG.
+// Цикл прерывается, когда ‖r‖ ≤ tol, когда шаг выходит на границу ‖p‖ = Δ или
+// когда dᵀBd ≤ 0 - тогда шаг продолжается вдоль d до границы области.
+// Возвращает шаг, причину остановки и число произведений B·d.
+func SteihaugCG(g []float64, hv func([]float64) []float64, radius, tol float64, maxIter int) ([]float64, CGStop, int) {
+	it := common_funcs.NewCGIteration(hv, common_funcs.ScalarMult(-1.0, g), make([]float64, len(g)), nil)
+	if it.ResidualNorm() <= tol {
+		return it.X, CGConverged, 0
+	}
+
+	for j := 0; j < maxIter; j++ {
+		if it.Curvature() <= 0 {
+			// Модель не ограничена снизу вдоль d - идем до границы
+			tau := boundaryStep(it.X, it.P, radius)
+			return common_funcs.VectorAdd(it.X, common_funcs.ScalarMult(tau, it.P)), CGNegativeCurvature, j + 1
+		}
+		pNext := common_funcs.VectorAdd(it.X, common_funcs.ScalarMult(it.Alpha(), it.P))
+		if common_funcs.VectorNorm(pNext) >= radius {
+			tau := boundaryStep(it.X, it.P, radius)
+			return common_funcs.VectorAdd(it.X, common_funcs.ScalarMult(tau, it.P)), CGBoundary, j + 1
+		}
+		it.Advance()
+		if it.ResidualNorm() <= tol {
+			return it.X, CGConverged, j + 1
+		}
+	}
+	return it.X, CGMaxIter, maxIter
+}
+
+// ForcingTolerance - допуск внутреннего цикла ‖r‖ ≤ η‖g‖ с η = min(0.5, √‖g‖),
+// дающий сверхлинейную сходимость внешнего метода Ньютона-CG.
+func ForcingTolerance(g []float64) float64 {
+	gNorm := common_funcs.VectorNorm(g)
+	return math.Min(0.5, math.Sqrt(gNorm)) * gNorm
+}
+
+// Steihaug - SteihaugCG в форме Subproblem для задач с явной матрицей B
+// (допуск ForcingTolerance, не более 2n внутренних итераций).
+func Steihaug(g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	hv := func(d []float64) []float64 { return common_funcs.MatrixVectorMult(b, d) }
+	p, _, _ := SteihaugCG(g, hv, radius, ForcingTolerance(g), 2*len(g))
+	return p
+}
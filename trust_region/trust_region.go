@@ -0,0 +1,73 @@
+// Package trust_region содержит общие части методов доверительной области:
+// решатели подзадачи min m(p) = gᵀp + ½pᵀBp при ‖p‖ ≤ Δ и правило
+// изменения радиуса Δ по отношению фактического и предсказанного уменьшения.
+package trust_region
+
+import (
+	"math"
+	"optimizationMethodsTask4/common_funcs"
+)
+
+// Subproblem - решатель подзадачи доверительной области для модели с
+// градиентом g и матрицей B (Гессианом или его приближением) при радиусе radius.
+type Subproblem func(g []float64, b common_funcs.Matrix, radius float64) []float64
+
+// Параметры правила изменения радиуса (Нокедал, Райт, алгоритм 4.1)
+const (
+	AcceptRatio = 0.1  // Шаг принимается, если ρ > AcceptRatio
+	ShrinkRatio = 0.25 // При ρ < ShrinkRatio радиус уменьшается
+	GrowRatio   = 0.75 // При ρ > GrowRatio и шаге на границе радиус увеличивается
+)
+
+// ModelReduction вычисляет предсказанное моделью уменьшение функции
+// pred = -(gᵀp + ½pᵀBp) по градиенту g, шагу p и произведению bp = B·p.
+func ModelReduction(g, p, bp []float64) float64 {
+	return -(common_funcs.DotProduct(g, p) + 0.5*common_funcs.DotProduct(p, bp))
+}
+
+// UpdateRadius возвращает отношение ρ = actual/pred, новый радиус и признак
+// принятия шага длины stepNorm. Радиус уменьшается до четверти длины шага при
+// плохом согласии модели и удваивается (не более maxRadius), если модель
+// хорошо предсказала уменьшение, а шаг уперся в границу области.
+func UpdateRadius(actual, pred, radius, stepNorm, maxRadius float64) (rho, newRadius float64, accept bool) {
+	if pred <= 0 {
+		// Модель не предсказывает уменьшения - шаг бесполезен
+		return math.Inf(-1), ShrinkRatio * stepNorm, false
+	}
+	rho = actual / pred
+	newRadius = radius
+	switch {
+	case rho < ShrinkRatio:
+		newRadius = ShrinkRatio * stepNorm
+	case rho > GrowRatio && stepNorm >= 0.99*radius:
+		newRadius = math.Min(2*radius, maxRadius)
+	}
+	return rho, newRadius, rho > AcceptRatio
+}
+
+// CauchyPoint - минимум модели вдоль антиградиента внутри области:
+// p = -τ·(Δ/‖g‖)·g, τ = 1 при gᵀBg ≤ 0, иначе min(‖g‖³/(Δ·gᵀBg), 1).
+func CauchyPoint(g []float64, b common_funcs.Matrix, radius float64) []float64 {
+	gNorm := common_funcs.VectorNorm(g)
+	if gNorm == 0 {
+		return make([]float64, len(g))
+	}
+	gBg := common_funcs.DotProduct(g, common_funcs.MatrixVectorMult(b, g))
+	tau := 1.0
+	if gBg > 0 {
+		tau = math.Min(gNorm*gNorm*gNorm/(radius*gBg), 1)
+	}
+	return common_funcs.ScalarMult(-tau*radius/gNorm, g)
+}
+
+// boundaryStep находит τ ≥ 0 такое, что ‖a + τ·d‖ = radius (a внутри области).
+func boundaryStep(a, d []float64, radius float64) float64 {
+	dd := common_funcs.DotProduct(d, d)
+	ad := common_funcs.DotProduct(a, d)
+	aa := common_funcs.DotProduct(a, a)
+	if dd == 0 {
+		return 0
+	}
+	disc := math.Max(ad*ad-dd*(aa-radius*radius), 0)
+	return (-ad + math.Sqrt(disc)) / dd
+}
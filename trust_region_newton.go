@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"optimizationMethodsTask4/common_funcs"
+	"optimizationMethodsTask4/expr"
+	"optimizationMethodsTask4/trust_region"
+	"os"
+)
+
+// trustRegionNewton реализует метод Ньютона с доверительной областью.
+// На каждой итерации квадратичная модель с точным Гессианом минимизируется
+// в шаре радиуса Δ, а радиус изменяется по отношению фактического и
+// предсказанного уменьшения функции. В отличие от одномерного поиска,
+// неопределенный Гессиан не требует перехода на антиградиент.
+// obj - целевая функция (должна уметь вычислять Гессиан).
+// startPoint - начальная точка.
+// epsilon - точность (норма градиента).
+// maxIter - максимальное количество итераций (пробных шагов).
+// initialRadius - начальный радиус доверительной области.
+// maxRadius - наибольший допустимый радиус.
+// subproblem - решатель подзадачи (Dogleg, DoubleDogleg, MoreSorensen, Steihaug).
+// Возвращает найденную точку минимума, количество итераций и историю радиусов.
+func trustRegionNewton(obj common_funcs.HessianObjective, startPoint []float64, epsilon float64, maxIter int, initialRadius, maxRadius float64, subproblem trust_region.Subproblem) ([]float64, int, []float64) {
+	x := make([]float64, len(startPoint))
+	copy(x, startPoint)
+	iter := 0
+	radius := initialRadius
+	var radii []float64 // Радиус доверительной области на каждой итерации
+
+	f := obj.Value(x)
+	grad := obj.Gradient(x)
+	hess := obj.Hessian(x)
+
+	// Основной цикл метода
+	for iter < maxIter {
+		// Критерий остановки
+		if common_funcs.VectorNorm(grad) < epsilon {
+			break
+		}
+		radii = append(radii, radius)
+
+		// 1. Решаем подзадачу: min gᵀp + ½pᵀHp при ‖p‖ ≤ Δ
+		step := subproblem(grad, hess, radius)
+		stepNorm := common_funcs.VectorNorm(step)
+
+		// 2. Сравниваем предсказанное моделью и фактическое уменьшение функции
+		pred := trust_region.ModelReduction(grad, step, common_funcs.MatrixVectorMult(hess, step))
+		xNext := common_funcs.VectorAdd(x, step)
+		fNext := obj.Value(xNext)
+		_, newRadius, accept := trust_region.UpdateRadius(f-fNext, pred, radius, stepNorm, maxRadius)
+
+		// 3. Принимаем шаг, только если модель достаточно хорошо предсказала уменьшение
+		if accept {
+			x = xNext
+			f = fNext
+			grad = obj.Gradient(x)
+			hess = obj.Hessian(x)
+		}
+		radius = newRadius
+		iter++
+	}
+
+	// Сообщение, если достигнуто максимальное количество итераций
+	if iter == maxIter {
+		fmt.Println("Метод доверительной области достиг максимального числа итераций.")
+	}
+	return x, iter, radii // Возвращаем результат
+}
+
+func main() {
+	formula := flag.String("f", "", "целевая функция в виде формулы, например \"x1^2 + 2*x2^2\"")
+	formulaFile := flag.String("file", "", "файл с формулой целевой функции")
+	flag.Parse()
+
+	// Целевая функция: вариант 17.164 или формула из командной строки/файла
+	var obj common_funcs.HessianObjective = common_funcs.Task17164
+	parsed, err := expr.Load(*formula, *formulaFile)
+	if err != nil {
+		fmt.Println("Ошибка в формуле целевой функции:", err)
+		os.Exit(1)
+	}
+	if parsed != nil {
+		obj = parsed
+	}
+
+	startPoint := make([]float64, obj.Dimension()) // Начальная точка (нулевая)
+	epsilon := 1e-5                                // Точность
+	maxIter := 200                                 // Макс. итераций
+	initialRadius := 1.0                           // Начальный радиус доверительной области
+	maxRadius := 100.0                             // Максимальный радиус
+
+	subproblems := []struct {
+		name  string
+		solve trust_region.Subproblem
+	}{
+		{"Dogleg", trust_region.Dogleg},
+		{"двойной Dogleg", trust_region.DoubleDogleg},
+		{"Море-Соренсен", trust_region.MoreSorensen},
+		{"Штайхауг-Тоинт", trust_region.Steihaug},
+	}
+	for _, sp := range subproblems {
+		// Вызываем метод
+		minX, iterations, radii := trustRegionNewton(obj, startPoint, epsilon, maxIter, initialRadius, maxRadius, sp.solve)
+		minF := obj.Value(minX) // Значение функции в минимуме
+
+		// Выводим результаты
+		fmt.Printf("\nМетод Ньютона с доверительной областью (%s):\n", sp.name)
+		fmt.Println("Найденный минимум x:", common_funcs.FormatVector(minX))
+		fmt.Printf("Значение функции в минимуме f(x): %.6f\n", minF)
+		fmt.Printf("Количество итераций: %d\n", iterations)
+		fmt.Printf("История радиусов: %.4g\n", radii)
+	}
+}